@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"polyglot-codebase/go-service/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidationErrors serves GET /admin/validation-errors: the logged
+// validation failures (see middleware.GetValidationErrors), optionally
+// narrowed by the "field", "since", and "until" query parameters, streamed
+// as newline-delimited JSON so a large log can be consumed without
+// buffering the whole response.
+func (h *Handler) ValidationErrors(c *gin.Context) {
+	since, ok := parseTimeQuery(c, "since")
+	if !ok {
+		return
+	}
+	until, ok := parseTimeQuery(c, "until")
+	if !ok {
+		return
+	}
+
+	errs := middleware.FilterValidationErrors(middleware.GetValidationErrors(), c.Query("field"), since, until)
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "application/x-ndjson")
+
+	enc := json.NewEncoder(c.Writer)
+	for _, e := range errs {
+		_ = enc.Encode(e)
+	}
+}
+
+// parseTimeQuery parses the RFC3339 timestamp in c's key query parameter,
+// writing a 400 response and returning ok=false if it's present but
+// malformed. A missing parameter yields the zero time.Time and ok=true,
+// which FilterValidationErrors treats as an unbounded side of the range.
+func parseTimeQuery(c *gin.Context, key string) (time.Time, bool) {
+	v := c.Query(key)
+	if v == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " must be an RFC3339 timestamp"})
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheConfig controls the size and lifetime limits of a Cache.
+type CacheConfig struct {
+	// MaxEntries is the maximum number of entries to retain; 0 means
+	// unlimited.
+	MaxEntries int
+	// MaxBytes is the maximum approximate total size of cached values in
+	// bytes; 0 means unlimited.
+	MaxBytes int64
+	// DefaultTTL is used by GetOrCompute when no per-call TTL is given.
+	DefaultTTL time.Duration
+	// JanitorInterval is how often expired entries are swept in the
+	// background; 0 disables the janitor.
+	JanitorInterval time.Duration
+}
+
+// DefaultCacheConfig returns the cache limits used by NewHandler.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		MaxEntries:      1000,
+		MaxBytes:        64 << 20, // 64MB
+		DefaultTTL:      5 * time.Minute,
+		JanitorInterval: time.Minute,
+	}
+}
+
+// CacheStats is a point-in-time snapshot of cache activity.
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Coalesced int64 `json:"coalesced"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// Prometheus counters for cache activity. They're registered once at
+// package init (rather than per Cache) since prometheus.DefaultRegisterer
+// panics on duplicate registration, and every Cache in this process shares
+// the same exported series - accurate for the single long-lived Handler.cache
+// this service actually runs, at the cost of accumulating across the
+// short-lived Cache instances the tests construct.
+var (
+	cacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "go_parser_cache_hits_total",
+		Help: "Total number of cache lookups that found a live entry.",
+	})
+	cacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "go_parser_cache_misses_total",
+		Help: "Total number of cache lookups that found no live entry.",
+	})
+	cacheCoalescedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "go_parser_cache_coalesced_total",
+		Help: "Total number of GetOrCompute calls that shared another caller's in-flight compute.",
+	})
+	cacheEvictionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "go_parser_cache_evictions_total",
+		Help: "Total number of entries evicted for exceeding MaxEntries/MaxBytes.",
+	})
+	cacheSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "go_parser_cache_entries",
+		Help: "Current number of entries in the cache.",
+	})
+	cacheBytesGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "go_parser_cache_bytes",
+		Help: "Current approximate total size of cached values in bytes.",
+	})
+)
+
+type cacheItem struct {
+	key       string
+	data      interface{}
+	raw       []byte
+	size      int64
+	expiresAt time.Time
+	cachedAt  time.Time
+}
+
+type cachedResult struct {
+	data     interface{}
+	raw      []byte
+	cachedAt time.Time
+}
+
+// Cache is a bounded, LRU-evicted cache with TTL expiry. Concurrent misses
+// for the same key are coalesced through a singleflight.Group so that a
+// burst of identical requests triggers exactly one computation instead of
+// stampeding whatever is behind GetOrCompute.
+type Cache struct {
+	cfg CacheConfig
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+	bytes   int64
+
+	group singleflight.Group
+
+	hits      int64
+	misses    int64
+	coalesced int64
+	evictions int64
+
+	stopCh chan struct{}
+}
+
+// NewCache builds a Cache from cfg and starts its background janitor, if
+// configured.
+func NewCache(cfg CacheConfig) *Cache {
+	c := &Cache{
+		cfg:     cfg,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+		stopCh:  make(chan struct{}),
+	}
+	if cfg.JanitorInterval > 0 {
+		go c.runJanitor(cfg.JanitorInterval)
+	}
+	return c
+}
+
+// Stop terminates the background janitor goroutine. It is not required for
+// correctness, only to let a Cache be garbage collected promptly.
+func (c *Cache) Stop() {
+	close(c.stopCh)
+}
+
+// Get returns the value stored under key, its serialized form (so range
+// requests can slice it without re-marshaling), the time it was cached, and
+// whether it was found and unexpired. A hit moves the entry to the front of
+// the LRU list.
+func (c *Cache) Get(key string) (data interface{}, raw []byte, cachedAt time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[key]
+	if !found {
+		atomic.AddInt64(&c.misses, 1)
+		cacheMissesTotal.Inc()
+		return nil, nil, time.Time{}, false
+	}
+
+	item := el.Value.(*cacheItem)
+	if time.Now().After(item.expiresAt) {
+		c.removeElement(el)
+		atomic.AddInt64(&c.misses, 1)
+		cacheMissesTotal.Inc()
+		return nil, nil, time.Time{}, false
+	}
+
+	c.ll.MoveToFront(el)
+	atomic.AddInt64(&c.hits, 1)
+	cacheHitsTotal.Inc()
+	return item.data, item.raw, item.cachedAt, true
+}
+
+// Set inserts or replaces key with data, expiring after ttl, and returns
+// the JSON bytes it was serialized to (so later range requests, see
+// writeRangeableJSON, reuse them instead of re-marshaling). Entries are
+// evicted from the back of the LRU list as needed to stay within
+// MaxEntries/MaxBytes.
+func (c *Cache) Set(key string, data interface{}, ttl time.Duration) []byte {
+	raw := marshalForCache(data)
+	size := int64(len(raw))
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		old := el.Value.(*cacheItem)
+		c.bytes -= old.size
+		el.Value = &cacheItem{key: key, data: data, raw: raw, size: size, expiresAt: now.Add(ttl), cachedAt: now}
+		c.bytes += size
+		c.ll.MoveToFront(el)
+	} else {
+		el := c.ll.PushFront(&cacheItem{key: key, data: data, raw: raw, size: size, expiresAt: now.Add(ttl), cachedAt: now})
+		c.entries[key] = el
+		c.bytes += size
+	}
+
+	c.evictLocked()
+	cacheSizeGauge.Set(float64(c.ll.Len()))
+	cacheBytesGauge.Set(float64(c.bytes))
+	return raw
+}
+
+// Status values returned by GetOrCompute, matching the X-Cache-Hit header
+// the api package exposes to callers.
+const (
+	StatusHit       = "true"
+	StatusMiss      = "false"
+	StatusCoalesced = "coalesced"
+)
+
+// GetOrCompute returns the cached value (and its serialized bytes) for key,
+// computing and storing it via fn on a miss. Concurrent callers that miss
+// on the same key share a single call to fn: the caller that actually
+// invokes it gets StatusMiss, everyone else waiting on that call gets
+// StatusCoalesced, and anyone who found a live entry gets StatusHit. age is
+// how long the returned value has been in the cache (zero for a fresh
+// miss).
+func (c *Cache) GetOrCompute(key string, ttl time.Duration, fn func() (interface{}, error)) (data interface{}, raw []byte, status string, age time.Duration, err error) {
+	if v, vraw, cachedAt, ok := c.Get(key); ok {
+		return v, vraw, StatusHit, time.Since(cachedAt), nil
+	}
+
+	// singleflight.Group.Do's own "shared" return value reports whether any
+	// duplicate joined the call by the time it finished - true for the
+	// leader too whenever fanout occurred, not just for followers. Track the
+	// leader explicitly instead: fn below only runs in the one call that
+	// actually wins the race for key, so leader is only set true there.
+	if ttl <= 0 {
+		ttl = c.cfg.DefaultTTL
+	}
+
+	var leader bool
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		leader = true
+		result, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		resultRaw := c.Set(key, result, ttl)
+		return cachedResult{data: result, raw: resultRaw, cachedAt: time.Now()}, nil
+	})
+	if err != nil {
+		return nil, nil, StatusMiss, 0, err
+	}
+
+	res := v.(cachedResult)
+	if !leader {
+		atomic.AddInt64(&c.coalesced, 1)
+		cacheCoalescedTotal.Inc()
+		return res.data, res.raw, StatusCoalesced, time.Since(res.cachedAt), nil
+	}
+	return res.data, res.raw, StatusMiss, time.Since(res.cachedAt), nil
+}
+
+// Reset empties the cache, discarding all entries without touching the
+// running hit/miss/eviction counters. See ResetStats to also zero those.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.entries = make(map[string]*list.Element)
+	c.bytes = 0
+
+	cacheSizeGauge.Set(0)
+	cacheBytesGauge.Set(0)
+}
+
+// ResetStats zeroes the hit/miss/coalesced/eviction counters, leaving
+// cached entries untouched. Handler.ClearCache calls this alongside Reset
+// so `POST /cache/clear` gives callers a clean slate on both.
+func (c *Cache) ResetStats() {
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.coalesced, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats returns a snapshot of the cache's activity counters and current
+// size/byte usage.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	size := c.ll.Len()
+	bytes := c.bytes
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Coalesced: atomic.LoadInt64(&c.coalesced),
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Size:      size,
+		Bytes:     bytes,
+	}
+}
+
+// evictLocked removes entries from the back of the LRU list until the
+// cache is back within its configured limits. c.mu must be held.
+func (c *Cache) evictLocked() {
+	for c.overCapacityLocked() {
+		el := c.ll.Back()
+		if el == nil {
+			return
+		}
+		c.removeElement(el)
+		atomic.AddInt64(&c.evictions, 1)
+		cacheEvictionsTotal.Inc()
+	}
+}
+
+func (c *Cache) overCapacityLocked() bool {
+	if c.cfg.MaxEntries > 0 && c.ll.Len() > c.cfg.MaxEntries {
+		return true
+	}
+	if c.cfg.MaxBytes > 0 && c.bytes > c.cfg.MaxBytes {
+		return true
+	}
+	return false
+}
+
+// removeElement drops el from the list, index, and byte count. c.mu must be
+// held.
+func (c *Cache) removeElement(el *list.Element) {
+	item := el.Value.(*cacheItem)
+	c.ll.Remove(el)
+	delete(c.entries, item.key)
+	c.bytes -= item.size
+}
+
+func (c *Cache) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.sweepExpired()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// sweepExpired removes all expired entries regardless of LRU position, so
+// memory is reclaimed even for keys nobody is reading anymore.
+func (c *Cache) sweepExpired() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		if item := el.Value.(*cacheItem); now.After(item.expiresAt) {
+			c.removeElement(el)
+		}
+		el = next
+	}
+
+	cacheSizeGauge.Set(float64(c.ll.Len()))
+	cacheBytesGauge.Set(float64(c.bytes))
+}
+
+// marshalForCache serializes data to the bytes stored alongside it in the
+// cache, used both for byte-based eviction and to let range requests slice
+// a cached response without re-marshaling it.
+func marshalForCache(data interface{}) []byte {
+	if b, err := json.Marshal(data); err == nil {
+		return b
+	}
+	return []byte(fmt.Sprintf("%v", data))
+}
@@ -5,27 +5,26 @@ import (
 	"encoding/hex"
 	"net/http"
 	"polyglot-codebase/go-service/internal/parser"
-	"sync"
+	"polyglot-codebase/go-service/middleware"
+	"polyglot-codebase/go-service/tracing"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-type CacheEntry struct {
-	Data      interface{}
-	ExpiresAt time.Time
-}
-
 type Handler struct {
-	parser *parser.Parser
-	cache  map[string]CacheEntry
-	mu     sync.RWMutex
+	parser       *parser.Parser
+	cache        *Cache
+	streamUpload StreamUploadConfig
 }
 
 func NewHandler() *Handler {
 	return &Handler{
-		parser: parser.NewParser(),
-		cache:  make(map[string]CacheEntry),
+		parser:       parser.NewParser(),
+		cache:        NewCache(DefaultCacheConfig()),
+		streamUpload: DefaultStreamUploadConfig(),
 	}
 }
 
@@ -41,6 +40,7 @@ type DiffRequest struct {
 
 type MetricsRequest struct {
 	Content string `json:"content" binding:"required"`
+	Path    string `json:"path"`
 }
 
 func (h *Handler) ParseFile(c *gin.Context) {
@@ -50,23 +50,26 @@ func (h *Handler) ParseFile(c *gin.Context) {
 		return
 	}
 
-	cacheKey := h.generateCacheKey("parse", req.Content+req.Path)
+	start := time.Now()
+	defer func() { parseLatencySeconds.Observe(time.Since(start).Seconds()) }()
 
-	if cached, found := h.getFromCache(cacheKey); found {
-		c.Header("X-Cache-Hit", "true")
-		c.JSON(http.StatusOK, cached)
-		return
-	}
+	cacheKey := h.generateCacheKey("parse", req.Content+req.Path)
 
-	file, err := h.parser.ParseFile(req.Content, req.Path)
+	data, raw, status, age, err := h.cache.GetOrCompute(cacheKey, 5*time.Minute, func() (interface{}, error) {
+		return h.parser.ParseFileContext(c.Request.Context(), req.Content, req.Path)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	h.setCache(cacheKey, file, 5*time.Minute)
-	c.Header("X-Cache-Hit", "false")
-	c.JSON(http.StatusOK, file)
+	if file, ok := data.(*parser.CodeFile); ok {
+		annotateSpan(c, file.Language, file.Size, len(file.Lines))
+	}
+
+	c.Header("X-Cache-Hit", status)
+	c.Header("X-Cache-Age", strconv.FormatFloat(age.Seconds(), 'f', -1, 64))
+	writeRangeableJSON(c, raw)
 }
 
 func (h *Handler) AnalyzeDiff(c *gin.Context) {
@@ -76,7 +79,10 @@ func (h *Handler) AnalyzeDiff(c *gin.Context) {
 		return
 	}
 
-	diff, err := h.parser.AnalyzeDiff(req.OldContent, req.NewContent)
+	start := time.Now()
+	defer func() { diffLatencySeconds.Observe(time.Since(start).Seconds()) }()
+
+	diff, err := h.parser.AnalyzeDiffContext(c.Request.Context(), req.OldContent, req.NewContent)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -92,65 +98,124 @@ func (h *Handler) CalculateMetrics(c *gin.Context) {
 		return
 	}
 
-	cacheKey := h.generateCacheKey("metrics", req.Content)
+	cacheKey := h.generateCacheKey("metrics", req.Content+req.Path)
 
-	if cached, found := h.getFromCache(cacheKey); found {
-		c.Header("X-Cache-Hit", "true")
-		c.JSON(http.StatusOK, cached)
+	data, raw, status, age, err := h.cache.GetOrCompute(cacheKey, 5*time.Minute, func() (interface{}, error) {
+		return h.parser.CalculateMetricsContext(c.Request.Context(), req.Content, req.Path)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	metrics := h.parser.CalculateMetrics(req.Content)
-	h.setCache(cacheKey, metrics, 5*time.Minute)
-	c.Header("X-Cache-Hit", "false")
-	c.JSON(http.StatusOK, metrics)
+	if metrics, ok := data.(*parser.CodeMetrics); ok {
+		annotateSpan(c, parser.DetectLanguage(req.Path), len(req.Content), metrics.TotalLines)
+	}
+
+	c.Header("X-Cache-Hit", status)
+	c.Header("X-Cache-Age", strconv.FormatFloat(age.Seconds(), 'f', -1, 64))
+	writeRangeableJSON(c, raw)
 }
 
-func (h *Handler) HealthCheck(c *gin.Context) {
+// Liveness serves GET /livez: it reports healthy as soon as the process is
+// up, with no dependency checks, so an orchestrator restarts the container
+// only when the process itself is wedged - not whenever a downstream
+// dependency used by Readiness is briefly unavailable.
+func (h *Handler) Liveness(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
+		"status":  "alive",
 		"service": "go-parser",
 	})
 }
 
-func (h *Handler) generateCacheKey(prefix, data string) string {
-	hash := sha256.Sum256([]byte(data))
-	return prefix + "_" + hex.EncodeToString(hash[:])
-}
-
-func (h *Handler) getFromCache(key string) (interface{}, bool) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// Readiness serves GET /readyz: it reports whether h is ready to take
+// traffic - the parser is constructed, the cache responds, and the
+// validation subsystem (see middleware.PolicySetReady) has a PolicySet
+// loaded - so an orchestrator can hold back traffic during startup without
+// conflating "not ready yet" with Liveness' "process is dead, restart it".
+func (h *Handler) Readiness(c *gin.Context) {
+	checks := map[string]bool{
+		"parser":     h.parser != nil,
+		"cache":      h.cacheReachable(),
+		"validation": middleware.PolicySetReady(),
+	}
 
-	entry, exists := h.cache[key]
-	if !exists {
-		return nil, false
+	ready := true
+	for _, ok := range checks {
+		if !ok {
+			ready = false
+			break
+		}
 	}
 
-	if time.Now().After(entry.ExpiresAt) {
-		return nil, false
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
 	}
 
-	return entry.Data, true
+	c.JSON(status, gin.H{
+		"ready":  ready,
+		"checks": checks,
+	})
 }
 
-func (h *Handler) setCache(key string, data interface{}, ttl time.Duration) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	h.cache[key] = CacheEntry{
-		Data:      data,
-		ExpiresAt: time.Now().Add(ttl),
+// cacheReachable reports whether h's cache will serve a request, by
+// exercising the same Stats call CacheStats/CacheMetrics rely on.
+func (h *Handler) cacheReachable() bool {
+	if h.cache == nil {
+		return false
 	}
+	h.cache.Stats()
+	return true
 }
 
-func (h *Handler) ClearCache(c *gin.Context) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+func (h *Handler) generateCacheKey(prefix, data string) string {
+	hash := sha256.Sum256([]byte(data))
+	return prefix + "_" + hex.EncodeToString(hash[:])
+}
 
-	h.cache = make(map[string]CacheEntry)
+func (h *Handler) ClearCache(c *gin.Context) {
+	h.cache.Reset()
+	h.cache.ResetStats()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Cache cleared successfully",
 	})
 }
+
+// CacheStats reports hit/miss/coalesced/eviction counters and the current
+// number of cached entries and bytes.
+func (h *Handler) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
+
+// CacheMetrics exposes the same counters as CacheStats in Prometheus text
+// exposition format, for scraping rather than polling. It serves the whole
+// default Prometheus registry, so the response also carries every other
+// series this process registers.
+func (h *Handler) CacheMetrics(c *gin.Context) {
+	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// PrometheusMetrics exposes this service's full set of Prometheus series -
+// parse/diff latency, validation error counts, and the cache counters also
+// served at /cache/metrics - for scraping by a standard observability
+// stack. Unlike /cache/metrics, it refreshes the validation error gauge
+// before serving.
+func (h *Handler) PrometheusMetrics(c *gin.Context) {
+	refreshValidationErrorsGauge()
+	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// annotateSpan tags the in-flight span tracing.Core.Gin recorded for this
+// request with parser-specific attributes. It's a no-op if that middleware
+// isn't installed on the router serving c.
+func annotateSpan(c *gin.Context, language string, sizeBytes, lineCount int) {
+	span, ok := tracing.SpanFromGinContext(c)
+	if !ok {
+		return
+	}
+	span.SetAttribute("parser.language", language)
+	span.SetAttribute("parser.file_size_bytes", strconv.Itoa(sizeBytes))
+	span.SetAttribute("parser.line_count", strconv.Itoa(lineCount))
+}
@@ -3,25 +3,36 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"polyglot-codebase/go-service/middleware"
+	"polyglot-codebase/go-service/tracing"
 )
 
 func setupRouter(h *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
+	r.Use(tracing.NewCore().Gin())
 	r.POST("/parse", h.ParseFile)
+	r.POST("/parse/stream", h.StreamParse)
 	r.POST("/diff", h.AnalyzeDiff)
 	r.POST("/metrics", h.CalculateMetrics)
-	r.GET("/health", h.HealthCheck)
+	r.GET("/metrics", h.PrometheusMetrics)
+	r.GET("/livez", h.Liveness)
+	r.GET("/readyz", h.Readiness)
 	r.POST("/cache/clear", h.ClearCache)
+	r.GET("/cache/stats", h.CacheStats)
+	r.GET("/cache/metrics", h.CacheMetrics)
+	r.GET("/admin/validation-errors", h.ValidationErrors)
 	return r
 }
 
@@ -37,12 +48,12 @@ func performJSONRequest(r *gin.Engine, method, path string, body interface{}) *h
 	return w
 }
 
-func TestHealthCheck_ReturnsHealthy(t *testing.T) {
+func TestLiveness_ReturnsAlive(t *testing.T) {
 	h := NewHandler()
 	r := setupRouter(h)
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest("GET", "/health", nil)
+	req := httptest.NewRequest("GET", "/livez", nil)
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -51,17 +62,38 @@ func TestHealthCheck_ReturnsHealthy(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "healthy", resp["status"])
+	assert.Equal(t, "alive", resp["status"])
 	assert.Equal(t, "go-parser", resp["service"])
 }
 
+func TestReadiness_ReturnsReadyWhenDependenciesOK(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Ready  bool            `json:"ready"`
+		Checks map[string]bool `json:"checks"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Ready)
+	assert.True(t, resp.Checks["parser"])
+	assert.True(t, resp.Checks["cache"])
+	assert.True(t, resp.Checks["validation"])
+}
+
 func TestClearCache_EmptiesCache(t *testing.T) {
 	h := NewHandler()
 	// seed cache
 	key := h.generateCacheKey("parse", "data")
-	h.setCache(key, map[string]string{"ok": "true"}, time.Minute)
+	h.cache.Set(key, map[string]string{"ok": "true"}, time.Minute)
 
-	assert.NotEmpty(t, h.cache)
+	assert.Equal(t, 1, h.cache.Stats().Size)
 
 	r := setupRouter(h)
 	w := httptest.NewRecorder()
@@ -69,7 +101,7 @@ func TestClearCache_EmptiesCache(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Empty(t, h.cache)
+	assert.Equal(t, 0, h.cache.Stats().Size)
 
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
@@ -115,7 +147,7 @@ func TestParseFile_CacheHit(t *testing.T) {
 	path := "file.txt"
 	cacheKey := h.generateCacheKey("parse", content+path)
 	expected := map[string]interface{}{"cached": true, "value": 123}
-	h.setCache(cacheKey, expected, time.Minute)
+	h.cache.Set(cacheKey, expected, time.Minute)
 
 	w := performJSONRequest(r, "POST", "/parse", map[string]string{
 		"content": content,
@@ -187,7 +219,7 @@ func TestCalculateMetrics_CacheHit(t *testing.T) {
 	content := "line1\n// comment\n"
 	cacheKey := h.generateCacheKey("metrics", content)
 	expected := map[string]interface{}{"cached": "metrics", "ok": true}
-	h.setCache(cacheKey, expected, time.Minute)
+	h.cache.Set(cacheKey, expected, time.Minute)
 
 	w := performJSONRequest(r, "POST", "/metrics", map[string]string{
 		"content": content,
@@ -218,14 +250,14 @@ func TestCache_SetGet_AndExpiry(t *testing.T) {
 	key := h.generateCacheKey("parse", "payload")
 	value := map[string]string{"v": "1"}
 
-	h.setCache(key, value, 10*time.Millisecond)
-	got, ok := h.getFromCache(key)
+	h.cache.Set(key, value, 10*time.Millisecond)
+	got, _, _, ok := h.cache.Get(key)
 	assert.True(t, ok)
 	assert.Equal(t, value, got)
 
 	// Expire
 	time.Sleep(20 * time.Millisecond)
-	got2, ok2 := h.getFromCache(key)
+	got2, _, _, ok2 := h.cache.Get(key)
 	assert.False(t, ok2)
 	assert.Nil(t, got2)
 }
@@ -254,7 +286,7 @@ func TestCache_ConcurrencySafety(t *testing.T) {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			h.setCache(key, map[string]int{"a": i}, time.Second)
+			h.cache.Set(key, map[string]int{"a": i}, time.Second)
 		}(i)
 	}
 	// Multiple readers
@@ -262,12 +294,325 @@ func TestCache_ConcurrencySafety(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, _ = h.getFromCache(key)
+			_, _, _, _ = h.cache.Get(key)
 		}()
 	}
 	wg.Wait()
 
-	got, ok := h.getFromCache(key)
+	got, _, _, ok := h.cache.Get(key)
 	assert.True(t, ok)
 	assert.IsType(t, value, got)
 }
+
+func TestCache_GetOrCompute_ConcurrentFanout_CoalescesToSingleCompute(t *testing.T) {
+	h := NewHandler()
+	key := h.generateCacheKey("parse", "fanout")
+
+	var calls int32
+	start := make(chan struct{})
+	statuses := make([]string, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, _, status, _, err := h.cache.GetOrCompute(key, time.Minute, func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond) // simulate slow ParseFile work
+				return "parsed", nil
+			})
+			assert.NoError(t, err)
+			statuses[i] = status
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	var misses, coalesced int
+	for _, status := range statuses {
+		switch status {
+		case StatusMiss:
+			misses++
+		case StatusCoalesced:
+			coalesced++
+		}
+	}
+	assert.Equal(t, 1, misses)
+	assert.Equal(t, 99, coalesced)
+}
+
+func TestParseFile_RangeRequest_ServesPartialContent(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	req := httptest.NewRequest("POST", "/parse", bytes.NewBufferString(`{"content":"a\nb\nc","path":"demo.txt"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Range", "bytes=0-4")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.NotEmpty(t, w.Header().Get("Content-Range"))
+	assert.Equal(t, 5, w.Body.Len())
+}
+
+func TestCacheStats_ReflectsHitsAndMisses(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	performJSONRequest(r, "POST", "/metrics", map[string]string{"content": "x\ny"})
+	performJSONRequest(r, "POST", "/metrics", map[string]string{"content": "x\ny"})
+
+	w := performJSONRequest(r, "GET", "/cache/stats", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats CacheStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.GreaterOrEqual(t, stats.Misses, int64(1))
+	assert.Equal(t, 1, stats.Size)
+	assert.Greater(t, stats.Bytes, int64(0))
+}
+
+func TestCache_Eviction_RemovesLeastRecentlyUsedFirst(t *testing.T) {
+	cache := NewCache(CacheConfig{MaxEntries: 2})
+
+	cache.Set("a", "1", time.Minute)
+	cache.Set("b", "2", time.Minute)
+	// Touch "a" so "b" becomes the least recently used entry.
+	_, _, _, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Set("c", "3", time.Minute)
+
+	_, _, _, aOK := cache.Get("a")
+	_, _, _, bOK := cache.Get("b")
+	_, _, _, cOK := cache.Get("c")
+
+	assert.True(t, aOK, "a was recently used, should survive eviction")
+	assert.False(t, bOK, "b was least recently used, should have been evicted")
+	assert.True(t, cOK)
+	assert.Equal(t, int64(1), cache.Stats().Evictions)
+}
+
+func TestCache_Eviction_EnforcesMaxBytes(t *testing.T) {
+	// Each entry here serializes to ~102 bytes, so MaxBytes of 150 leaves
+	// room for exactly one of them.
+	cache := NewCache(CacheConfig{MaxBytes: 150})
+
+	cache.Set("a", strings.Repeat("x", 100), time.Minute)
+	cache.Set("b", strings.Repeat("y", 100), time.Minute)
+
+	stats := cache.Stats()
+	assert.Equal(t, 1, stats.Size, "MaxBytes should only leave room for the most recent entry")
+	assert.Greater(t, stats.Evictions, int64(0))
+
+	_, _, _, aOK := cache.Get("a")
+	_, _, _, bOK := cache.Get("b")
+	assert.False(t, aOK)
+	assert.True(t, bOK)
+}
+
+func TestClearCache_AlsoResetsStats(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	performJSONRequest(r, "POST", "/metrics", map[string]string{"content": "x\ny"})
+	performJSONRequest(r, "POST", "/metrics", map[string]string{"content": "x\ny"})
+	assert.Greater(t, h.cache.Stats().Hits, int64(0))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/cache/clear", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stats := h.cache.Stats()
+	assert.Equal(t, int64(0), stats.Hits)
+	assert.Equal(t, int64(0), stats.Misses)
+	assert.Equal(t, int64(0), stats.Evictions)
+	assert.Equal(t, int64(0), stats.Coalesced)
+	assert.Equal(t, 0, stats.Size)
+}
+
+func TestCacheMetrics_ServesPrometheusExposition(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := performJSONRequest(r, "GET", "/cache/metrics", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "go_parser_cache_hits_total")
+}
+
+func TestPrometheusMetrics_ServesParseAndDiffLatencyAndValidationSeries(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	performJSONRequest(r, "POST", "/parse", map[string]string{"content": "a\nb", "path": "demo.go"})
+	performJSONRequest(r, "POST", "/diff", map[string]string{"old_content": "a", "new_content": "b"})
+
+	w := performJSONRequest(r, "GET", "/metrics", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "go_parser_parse_duration_seconds")
+	assert.Contains(t, body, "go_parser_diff_duration_seconds")
+	assert.Contains(t, body, "go_parser_validation_errors")
+}
+
+func TestParseFile_AnnotatesSpanWithLanguageSizeAndLineCount(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := performJSONRequest(r, "POST", "/parse", map[string]string{
+		"content": "a\nb\nc",
+		"path":    "demo.go",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	correlationID := w.Header().Get(middleware.CorrelationIDHeader)
+	traces := middleware.GetTraces(correlationID)
+	if assert.Len(t, traces, 1) {
+		attrs := traces[0].Attributes
+		assert.Equal(t, "go", attrs["parser.language"])
+		assert.Equal(t, "5", attrs["parser.file_size_bytes"])
+		assert.Equal(t, "3", attrs["parser.line_count"])
+	}
+}
+
+// multipartFileBody builds a multipart/form-data body with one "file" part
+// per entry in files, returning the body and the matching Content-Type
+// header value (including the boundary).
+func multipartFileBody(t *testing.T, files map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for name, content := range files {
+		part, err := w.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf, w.FormDataContentType()
+}
+
+func TestStreamParse_Success_EmitsProgressAndDoneEvents(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	body, contentType := multipartFileBody(t, map[string]string{"demo.go": "package main\n\nfunc main() {}\n"})
+
+	req := httptest.NewRequest("POST", "/parse/stream", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	out := w.Body.String()
+	assert.Contains(t, out, "event: progress")
+	assert.Contains(t, out, "event: done")
+	assert.Contains(t, out, `"TotalFiles":1`)
+	assert.Contains(t, out, `"go":1`)
+}
+
+func TestStreamParse_MultipleFiles_AccumulatesAcrossFiles(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	body, contentType := multipartFileBody(t, map[string]string{
+		"a.go": "package a\n",
+		"b.py": "print('hi')\n",
+	})
+
+	req := httptest.NewRequest("POST", "/parse/stream", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"TotalFiles":2`)
+}
+
+func TestStreamParse_FileExceedsMaxFileSize_EmitsErrorEvent(t *testing.T) {
+	h := NewHandler()
+	h.streamUpload.MaxFileSize = 10
+	r := setupRouter(h)
+
+	body, contentType := multipartFileBody(t, map[string]string{"big.txt": strings.Repeat("x", 100)})
+
+	req := httptest.NewRequest("POST", "/parse/stream", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event: error")
+	assert.Contains(t, w.Body.String(), "exceeds maximum size")
+}
+
+func TestStreamParse_NotMultipart_BadRequest(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	req := httptest.NewRequest("POST", "/parse/stream", bytes.NewBufferString("not multipart"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCalculateMetrics_AnnotatesSpanWithLanguageAndLineCount(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := performJSONRequest(r, "POST", "/metrics", map[string]string{
+		"content": "a\nb\nc\nd",
+		"path":    "demo.py",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	correlationID := w.Header().Get(middleware.CorrelationIDHeader)
+	traces := middleware.GetTraces(correlationID)
+	if assert.Len(t, traces, 1) {
+		attrs := traces[0].Attributes
+		assert.Equal(t, "python", attrs["parser.language"])
+		assert.Equal(t, "4", attrs["parser.line_count"])
+	}
+}
+
+func TestValidationErrors_StreamsFilteredNDJSON(t *testing.T) {
+	middleware.ClearValidationErrors()
+	t.Cleanup(middleware.ClearValidationErrors)
+
+	_ = middleware.ValidateParseRequest("", "demo.go")
+	_ = middleware.ValidateDiffRequest("", "")
+
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := performJSONRequest(r, "GET", "/admin/validation-errors?field=content", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 1, "only the ValidateParseRequest content error should match field=content")
+	for _, line := range lines {
+		var got middleware.ValidationError
+		assert.NoError(t, json.Unmarshal([]byte(line), &got))
+		assert.Equal(t, "content", got.Field)
+	}
+}
+
+func TestValidationErrors_InvalidSinceReturnsBadRequest(t *testing.T) {
+	h := NewHandler()
+	r := setupRouter(h)
+
+	w := performJSONRequest(r, "GET", "/admin/validation-errors?since=not-a-time", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"polyglot-codebase/go-service/middleware"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus histograms/gauges for request-level observability. Like the
+// cache counters in cache.go, these are registered once at package init
+// rather than per Handler, since promauto panics on duplicate registration
+// and every Handler in this process shares the same exported series.
+var (
+	parseLatencySeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "go_parser_parse_duration_seconds",
+		Help:    "Time taken to handle a /parse request, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+	diffLatencySeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "go_parser_diff_duration_seconds",
+		Help:    "Time taken to handle a /diff request, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+	validationErrorsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "go_parser_validation_errors",
+		Help: "Current number of recorded request validation errors (see middleware.GetValidationErrors).",
+	})
+)
+
+// refreshValidationErrorsGauge syncs validationErrorsGauge with the live
+// validation error log at scrape time, rather than trying to keep a
+// separate counter in lockstep with middleware.logValidationErrors.
+func refreshValidationErrorsGauge() {
+	validationErrorsGauge.Set(float64(len(middleware.GetValidationErrors())))
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ByteRange is an inclusive [Start, End] byte range resolved against a
+// concrete content length.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// writeRangeableJSON writes raw (an already-marshaled JSON document,
+// typically straight from the Cache) as the response body, honoring an
+// RFC 7233 Range request header. Without a Range header it serves the full
+// body as 200; with one it serves a single range as 206 with Content-Range,
+// multiple ranges as a 206 multipart/byteranges body, or 416 if none of the
+// requested ranges are satisfiable.
+func writeRangeableJSON(c *gin.Context, raw []byte) {
+	c.Header("Accept-Ranges", "bytes")
+
+	rangeHeader := c.GetHeader("Range")
+	if rangeHeader == "" {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+		return
+	}
+
+	size := int64(len(raw))
+	ranges, ok, satisfiable := parseRangeHeader(rangeHeader, size)
+	if !ok {
+		// Malformed Range header: RFC 7233 says to ignore it and serve the
+		// full representation.
+		c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+		return
+	}
+	if !satisfiable {
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", size))
+		c.Data(http.StatusRequestedRangeNotSatisfiable, "application/json; charset=utf-8", nil)
+		return
+	}
+
+	if len(ranges) == 1 {
+		br := ranges[0]
+		c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", br.Start, br.End, size))
+		c.Data(http.StatusPartialContent, "application/json; charset=utf-8", raw[br.Start:br.End+1])
+		return
+	}
+
+	body, boundary := buildByteRanges(raw, ranges, size)
+	c.Data(http.StatusPartialContent, "multipart/byteranges; boundary="+boundary, body)
+}
+
+// parseRangeHeader parses an RFC 7233 Range header ("bytes=0-4",
+// "bytes=-5", "bytes=0-1,5-8") against size bytes of content. ok is false
+// if the header is absent or malformed, meaning it should be ignored and
+// the full body served. satisfiable is false if every requested range fell
+// entirely outside of size, meaning 416 should be returned; ranges holds
+// whichever requested ranges were satisfiable (RFC 7233 §2.1: unsatisfiable
+// ranges in a set are dropped, not fatal, as long as at least one is
+// satisfiable).
+func parseRangeHeader(header string, size int64) (ranges []ByteRange, ok bool, satisfiable bool) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, false, false
+	}
+
+	specs := strings.Split(strings.TrimPrefix(header, prefix), ",")
+	if len(specs) == 0 {
+		return nil, false, false
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			return nil, false, false
+		}
+
+		br, isSatisfiable, valid := parseOneRange(spec, size)
+		if !valid {
+			return nil, false, false
+		}
+		if isSatisfiable {
+			ranges = append(ranges, br)
+		}
+	}
+
+	return ranges, true, len(ranges) > 0
+}
+
+// parseOneRange parses a single range-spec (without the "bytes=" prefix).
+// valid is false for syntactically invalid input. satisfiable is false for
+// well-formed ranges that fall entirely outside size (e.g. a start past the
+// end of the content, or a zero-length suffix).
+func parseOneRange(spec string, size int64) (br ByteRange, satisfiable bool, valid bool) {
+	dash := strings.IndexByte(spec, '-')
+	if dash < 0 {
+		return ByteRange{}, false, false
+	}
+
+	startStr, endStr := spec[:dash], spec[dash+1:]
+
+	if startStr == "" {
+		// Suffix range "-N": the last N bytes of the content.
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 {
+			return ByteRange{}, false, false
+		}
+		if n == 0 || size == 0 {
+			return ByteRange{}, false, true
+		}
+		if n > size {
+			n = size
+		}
+		return ByteRange{Start: size - n, End: size - 1}, true, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return ByteRange{}, false, false
+	}
+	if start >= size {
+		return ByteRange{}, false, true
+	}
+
+	end := size - 1
+	if endStr != "" {
+		e, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || e < start {
+			return ByteRange{}, false, false
+		}
+		if e < end {
+			end = e
+		}
+	}
+
+	return ByteRange{Start: start, End: end}, true, true
+}
+
+// buildByteRanges assembles a multipart/byteranges body (RFC 7233 §4.1) out
+// of raw's bytes for each requested range.
+func buildByteRanges(raw []byte, ranges []ByteRange, size int64) (body []byte, boundary string) {
+	boundary = randomBoundary()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.SetBoundary(boundary)
+
+	for _, br := range ranges {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/json")
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", br.Start, br.End, size))
+
+		part, _ := mw.CreatePart(header)
+		_, _ = part.Write(raw[br.Start : br.End+1])
+	}
+	_ = mw.Close()
+
+	return buf.Bytes(), boundary
+}
+
+func randomBoundary() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "gopartboundary"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 7233 test matrix, modeled on the examples in §2.1/§2.1.1 of the spec:
+// a 26-byte document ("abcdefghijklmnopqrstuvwxyz") sliced every way the
+// spec calls out explicitly.
+func TestParseRangeHeader_RFC7233Matrix(t *testing.T) {
+	const size = 26 // "abcdefghijklmnopqrstuvwxyz"
+
+	tests := []struct {
+		name       string
+		header     string
+		wantOK     bool
+		wantSat    bool
+		wantRanges []ByteRange
+	}{
+		{"range spanning the whole document", "bytes=0-25", true, true, []ByteRange{{0, 25}}},
+		{"first 10 bytes", "bytes=0-9", true, true, []ByteRange{{0, 9}}},
+		{"second 10 bytes", "bytes=10-19", true, true, []ByteRange{{10, 19}}},
+		{"final 9 bytes via explicit offset", "bytes=17-25", true, true, []ByteRange{{17, 25}}},
+		{"final 9 bytes via suffix", "bytes=-9", true, true, []ByteRange{{17, 25}}},
+		{"open-ended range to end of doc", "bytes=20-", true, true, []ByteRange{{20, 25}}},
+		{"end beyond size clamps to size-1", "bytes=0-1000", true, true, []ByteRange{{0, 25}}},
+		{"suffix larger than doc clamps to whole doc", "bytes=-1000", true, true, []ByteRange{{0, 25}}},
+		{"multi-range", "bytes=0-1,5-8", true, true, []ByteRange{{0, 1}, {5, 8}}},
+		{"multi-range with whitespace", "bytes=0-1, 5-8", true, true, []ByteRange{{0, 1}, {5, 8}}},
+		{"start at exact size is unsatisfiable", "bytes=26-", true, false, nil},
+		{"start past size is unsatisfiable", "bytes=100-200", true, false, nil},
+		{"zero-length suffix is unsatisfiable", "bytes=-0", true, false, nil},
+		{"one satisfiable, one not: only satisfiable kept", "bytes=0-1,100-200", true, true, []ByteRange{{0, 1}}},
+		{"missing bytes= prefix is malformed", "0-10", false, false, nil},
+		{"non-numeric start is malformed", "bytes=a-10", false, false, nil},
+		{"end before start is malformed", "bytes=10-5", false, false, nil},
+		{"empty header value is malformed", "bytes=", false, false, nil},
+		{"empty spec in list is malformed", "bytes=0-1,,5-8", false, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, ok, satisfiable := parseRangeHeader(tt.header, size)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantSat, satisfiable)
+			if tt.wantRanges != nil {
+				assert.Equal(t, tt.wantRanges, ranges)
+			}
+		})
+	}
+}
+
+func newRangeTestContext(t *testing.T, rangeHeader string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	c.Request = req
+	return c, rr
+}
+
+func TestWriteRangeableJSON_NoRangeHeader_Serves200WithFullBody(t *testing.T) {
+	raw := []byte(`{"hello":"world"}`)
+	c, rr := newRangeTestContext(t, "")
+
+	writeRangeableJSON(c, raw)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, raw, rr.Body.Bytes())
+	assert.Equal(t, "bytes", rr.Header().Get("Accept-Ranges"))
+}
+
+func TestWriteRangeableJSON_SingleRange_Serves206WithContentRange(t *testing.T) {
+	raw := []byte(`{"hello":"world"}`) // 17 bytes
+	c, rr := newRangeTestContext(t, "bytes=0-4")
+
+	writeRangeableJSON(c, raw)
+
+	assert.Equal(t, http.StatusPartialContent, rr.Code)
+	assert.Equal(t, raw[0:5], rr.Body.Bytes())
+	assert.Equal(t, "bytes 0-4/17", rr.Header().Get("Content-Range"))
+}
+
+func TestWriteRangeableJSON_UnsatisfiableRange_Serves416(t *testing.T) {
+	raw := []byte(`{"hello":"world"}`) // 17 bytes
+	c, rr := newRangeTestContext(t, "bytes=1000-2000")
+
+	writeRangeableJSON(c, raw)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rr.Code)
+	assert.Equal(t, "bytes */17", rr.Header().Get("Content-Range"))
+	assert.Empty(t, rr.Body.Bytes())
+}
+
+func TestWriteRangeableJSON_MalformedRange_ServesFullBody(t *testing.T) {
+	raw := []byte(`{"hello":"world"}`)
+	c, rr := newRangeTestContext(t, "not-a-range")
+
+	writeRangeableJSON(c, raw)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, raw, rr.Body.Bytes())
+}
+
+func TestWriteRangeableJSON_MultiRange_ServesMultipartByteranges(t *testing.T) {
+	raw := []byte(`{"hello":"world","ok":true}`)
+	c, rr := newRangeTestContext(t, "bytes=0-4,6-9")
+
+	writeRangeableJSON(c, raw)
+
+	assert.Equal(t, http.StatusPartialContent, rr.Code)
+
+	contentType := rr.Header().Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	mr := multipart.NewReader(rr.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes 0-4/27", part.Header.Get("Content-Range"))
+	assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+
+	part2, err := mr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes 6-9/27", part2.Header.Get("Content-Range"))
+
+	_, err = mr.NextPart()
+	assert.Error(t, err) // no third part
+}
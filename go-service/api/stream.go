@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"polyglot-codebase/go-service/internal/parser"
+	"polyglot-codebase/go-service/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamUploadConfig bounds a single streaming upload: the largest any one
+// file may be, and the largest the whole request body may be across all
+// files, both enforced as each file streams through rather than after the
+// fact.
+type StreamUploadConfig struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
+
+// DefaultStreamUploadConfig returns the limits StreamParse uses when a
+// Handler wasn't built with narrower ones. These are far above
+// middleware.MaxContentSize's 1MB JSON cap since a streamed multipart
+// upload never holds a whole file in memory at once.
+func DefaultStreamUploadConfig() StreamUploadConfig {
+	return StreamUploadConfig{
+		MaxFileSize:  100 << 20, // 100MB
+		MaxTotalSize: 1 << 30,   // 1GB
+	}
+}
+
+// StreamParse handles POST /parse/stream: a multipart/form-data upload of
+// one or more files, read one at a time as they arrive off the wire
+// instead of buffered whole into memory first. Progress is reported as
+// Server-Sent Events carrying a running FileStatistics snapshot, so a
+// client uploading a multi-hundred-MB repository can show progress without
+// waiting for the whole request to finish.
+func (h *Handler) StreamParse(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats := parser.NewStreamingStats()
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	var totalSize int64
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeSSEEvent(c, "error", gin.H{"error": err.Error()})
+			return
+		}
+
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		lines, size, err := streamFilePart(part, h.streamUpload.MaxFileSize)
+		fileName := part.FileName()
+		part.Close()
+		if err != nil {
+			writeSSEEvent(c, "error", gin.H{"file": fileName, "error": err.Error()})
+			return
+		}
+
+		totalSize += size
+		if h.streamUpload.MaxTotalSize > 0 && totalSize > h.streamUpload.MaxTotalSize {
+			writeSSEEvent(c, "error", gin.H{"error": "aggregate upload size exceeds limit"})
+			return
+		}
+
+		stats.Observe(fileName, lines, int(size), parser.DetectLanguage(fileName))
+
+		writeSSEEvent(c, "progress", stats.Snapshot())
+		c.Writer.Flush()
+	}
+
+	writeSSEEvent(c, "done", stats.Snapshot())
+	c.Writer.Flush()
+}
+
+// streamFilePart reads part one line at a time via a bufio.Scanner, so only
+// a line at a time is ever held in memory, sanitizing each line with
+// middleware.SanitizeInput before it's counted. It returns an error if part
+// isn't exhausted within maxFileSize bytes.
+func streamFilePart(part *multipart.Part, maxFileSize int64) (lines int, size int64, err error) {
+	limited := &io.LimitedReader{R: part, N: maxFileSize + 1}
+
+	scanner := bufio.NewScanner(limited)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := middleware.SanitizeInput(scanner.Text())
+		lines++
+		size += int64(len(line)) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return lines, size, err
+	}
+	if limited.N <= 0 {
+		return lines, size, fmt.Errorf("file %q exceeds maximum size of %d bytes", part.FileName(), maxFileSize)
+	}
+
+	return lines, size, nil
+}
+
+// writeSSEEvent writes data as a single Server-Sent Events frame of the
+// given event type.
+func writeSSEEvent(c *gin.Context, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload)
+}
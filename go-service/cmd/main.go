@@ -1,25 +1,143 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"os"
 	"polyglot-codebase/go-service/api"
+	"polyglot-codebase/go-service/middleware"
+	"polyglot-codebase/go-service/server"
+	"polyglot-codebase/go-service/tracing"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	loadPolicySet()
+
 	r := gin.Default()
+	r.Use(tracing.NewCore(tracingOptions()...).Gin())
 
 	handler := api.NewHandler()
 
-	r.GET("/health", handler.HealthCheck)
+	r.GET("/livez", handler.Liveness)
+	r.GET("/readyz", handler.Readiness)
 	r.POST("/parse", handler.ParseFile)
+	r.POST("/parse/stream", handler.StreamParse)
 	r.POST("/diff", handler.AnalyzeDiff)
 	r.POST("/metrics", handler.CalculateMetrics)
+	r.GET("/metrics", handler.PrometheusMetrics)
 	r.POST("/cache/clear", handler.ClearCache)
+	r.GET("/cache/stats", handler.CacheStats)
+	r.GET("/cache/metrics", handler.CacheMetrics)
+	r.GET("/admin/validation-errors", handler.ValidationErrors)
+
+	var handlerChain http.Handler = r
+	handlerChain = middleware.ValidationMiddleware(handlerChain)
+	handlerChain = middleware.DecompressMiddleware(handlerChain)
+	handlerChain = middleware.CompressMiddleware(middleware.DefaultCompressConfig())(handlerChain)
+	handlerChain = middleware.TimeoutMiddlewareWithMax(requestTimeout(), middleware.DefaultMaxTimeout)(handlerChain)
+	handlerChain = middleware.CORSMiddleware(middleware.DefaultCORSConfig())(handlerChain)
+
+	srv := server.New(handlerChain, serverConfig())
 
-	log.Println("Go Parser Service starting on :8080")
-	if err := r.Run(":8080"); err != nil {
+	log.Println("Go Parser Service starting on", srv.Addr())
+	if err := srv.ListenAndServe(context.Background()); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// requestTimeout is the per-request deadline middleware.TimeoutMiddleware
+// enforces (a client may request a shorter or longer one via the
+// X-Request-Timeout header, clamped to middleware.DefaultMaxTimeout), configurable via
+// REQUEST_TIMEOUT (e.g. "30s") so a runaway parse can't pin a goroutine
+// without recompiling to change the limit. Defaults to 30s.
+func requestTimeout() time.Duration {
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return 30 * time.Second
+}
+
+// loadPolicySet replaces the active validation PolicySet (see
+// middleware.ValidationMiddleware) with one loaded from VALIDATION_POLICY_PATH
+// (JSON or YAML, per middleware.LoadPolicySet), so operators can tighten or
+// loosen per-route rules without recompiling. Left at middleware.DefaultPolicySet
+// if the env var is unset, and a bad or unreadable file is logged and
+// skipped rather than aborting startup.
+func loadPolicySet() {
+	path := os.Getenv("VALIDATION_POLICY_PATH")
+	if path == "" {
+		return
+	}
+
+	ps, err := middleware.LoadPolicySet(path)
+	if err != nil {
+		log.Println("Failed to load validation policy set from", path, "- keeping defaults:", err)
+		return
+	}
+	middleware.SetPolicySet(ps)
+}
+
+// serverConfig is server.DefaultConfig with its listen address overridable
+// via LISTEN_ADDR, so the service can bind a different address in
+// containerized environments without recompiling.
+func serverConfig() server.Config {
+	cfg := server.DefaultConfig()
+	if addr := os.Getenv("LISTEN_ADDR"); addr != "" {
+		cfg.Addr = addr
+	}
+	return cfg
+}
+
+// tracingOptions builds the tracing.Core options that wire this service's
+// spans to an external OTLP or Jaeger collector, driven entirely by
+// environment variables so no code change is needed to point it at a
+// different observability stack:
+//
+//   - OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_JAEGER_ENDPOINT - collector
+//     URL; export is disabled if neither is set.
+//   - OTEL_EXPORTER_PROTOCOL - "otlp" (default) or "jaeger"; inferred from
+//     which endpoint var is set if omitted.
+//   - OTEL_SERVICE_NAME - defaults to "go-parser".
+//   - OTEL_TRACES_SAMPLER_ARG - export sample rate in [0, 1]; defaults to 1.0.
+//   - OTEL_CAPTURE_BODY - "true" to attach request/response bodies to spans.
+func tracingOptions() []tracing.Option {
+	var opts []tracing.Option
+
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	protocol := "otlp"
+	if endpoint == "" {
+		if jaegerEndpoint := os.Getenv("OTEL_EXPORTER_JAEGER_ENDPOINT"); jaegerEndpoint != "" {
+			endpoint = jaegerEndpoint
+			protocol = "jaeger"
+		}
+	}
+	if p := os.Getenv("OTEL_EXPORTER_PROTOCOL"); p != "" {
+		protocol = p
+	}
+
+	if endpoint != "" {
+		cfg := middleware.DefaultTracerConfig()
+		cfg.CollectorURL = endpoint
+		cfg.Protocol = protocol
+		if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+			cfg.ServiceName = name
+		}
+		if rate, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil {
+			cfg.SampleRate = rate
+		}
+		opts = append(opts, tracing.WithTracer(middleware.NewTracer(cfg)))
+	}
+
+	if os.Getenv("OTEL_CAPTURE_BODY") == "true" {
+		opts = append(opts, tracing.WithBodyCapture(0))
+	}
+
+	return opts
+}
@@ -0,0 +1,351 @@
+package parser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// LineKind classifies a single line of source for CodeMetrics' line-count
+// totals.
+type LineKind int
+
+const (
+	LineCode LineKind = iota
+	LineComment
+	LineBlank
+)
+
+// ClassifyState carries information between ClassifyLine calls for
+// constructs, such as block comments, that span more than one line.
+type ClassifyState struct {
+	InBlockComment bool
+}
+
+// FunctionComplexity is the McCabe cyclomatic complexity computed for a
+// single function or method.
+type FunctionComplexity struct {
+	Name       string
+	Complexity int
+	StartLine  int
+	EndLine    int
+}
+
+// LanguageAnalyzer computes language-aware source metrics. It replaces
+// substring checks like strings.Contains(line, "func ") with something that
+// actually understands the language's grammar (or, for languages we don't
+// have a real parser for, at least tokenizes it well enough to ignore
+// string literals and comments).
+type LanguageAnalyzer interface {
+	// CountFunctions returns the number of function/method declarations in
+	// content.
+	CountFunctions(content string) int
+	// CountClasses returns the number of class/type declarations in
+	// content.
+	CountClasses(content string) int
+	// CyclomaticComplexity returns the McCabe complexity of every function
+	// found in content (base complexity 1, +1 per decision point) along
+	// with the sum of those complexities.
+	CyclomaticComplexity(content string) (functions []FunctionComplexity, total int)
+	// ClassifyLine reports whether a single line is code, a comment, or
+	// blank. state must be reused across consecutive calls for the same
+	// file so multi-line constructs (block comments) classify correctly.
+	ClassifyLine(line string, state *ClassifyState) LineKind
+}
+
+// analyzerForLanguage returns the best LanguageAnalyzer available for a
+// detectLanguage result, falling back to a generic C-style regex analyzer
+// for languages (or unknown extensions) we don't have a real parser for.
+func analyzerForLanguage(language string) LanguageAnalyzer {
+	switch language {
+	case "go":
+		return NewGoAnalyzer()
+	case "python":
+		return NewPythonAnalyzer()
+	case "ruby":
+		return NewRubyAnalyzer()
+	case "javascript", "typescript":
+		return NewJavaScriptAnalyzer()
+	default:
+		return NewJavaScriptAnalyzer()
+	}
+}
+
+// parseGoSource parses Go source that may or may not already have a
+// package clause (CalculateMetrics is routinely handed bare function
+// bodies, not whole files). It first tries content as-is, and only falls
+// back to wrapping it in a synthetic package clause if that fails. The
+// returned lineOffset is the number of lines the wrapping added, so
+// callers can translate fset positions back to content's own line numbers.
+func parseGoSource(content string) (file *ast.File, fset *token.FileSet, lineOffset int, err error) {
+	fset = token.NewFileSet()
+	file, err = parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err == nil {
+		return file, fset, 0, nil
+	}
+
+	fset = token.NewFileSet()
+	file, err = parser.ParseFile(fset, "", "package p\n"+content, parser.ParseComments)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return file, fset, 1, nil
+}
+
+// GoAnalyzer is a LanguageAnalyzer backed by go/parser and go/ast, so it
+// understands Go's actual grammar instead of guessing from substrings.
+type GoAnalyzer struct{}
+
+func NewGoAnalyzer() *GoAnalyzer {
+	return &GoAnalyzer{}
+}
+
+func (a *GoAnalyzer) CountFunctions(content string) int {
+	file, _, _, err := parseGoSource(content)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, decl := range file.Decls {
+		if _, ok := decl.(*ast.FuncDecl); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *GoAnalyzer) CountClasses(content string) int {
+	file, _, _, err := parseGoSource(content)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		if _, ok := n.(*ast.TypeSpec); ok {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// CyclomaticComplexity walks each function's body, adding one to its base
+// complexity for every *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt,
+// *ast.CaseClause, *ast.CommClause, and short-circuiting &&/|| operand in a
+// *ast.BinaryExpr - each is an independent branch a test suite would need
+// to cover.
+func (a *GoAnalyzer) CyclomaticComplexity(content string) ([]FunctionComplexity, int) {
+	file, fset, lineOffset, err := parseGoSource(content)
+	if err != nil {
+		return nil, 0
+	}
+
+	var functions []FunctionComplexity
+	total := 0
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		complexity := 1
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.IfStmt:
+				complexity++
+			case *ast.ForStmt:
+				complexity++
+			case *ast.RangeStmt:
+				complexity++
+			case *ast.CaseClause:
+				complexity++
+			case *ast.CommClause:
+				complexity++
+			case *ast.BinaryExpr:
+				if node.Op == token.LAND || node.Op == token.LOR {
+					complexity++
+				}
+			}
+			return true
+		})
+
+		functions = append(functions, FunctionComplexity{
+			Name:       fn.Name.Name,
+			Complexity: complexity,
+			StartLine:  fset.Position(fn.Pos()).Line - lineOffset,
+			EndLine:    fset.Position(fn.End()).Line - lineOffset,
+		})
+		total += complexity
+	}
+
+	return functions, total
+}
+
+func (a *GoAnalyzer) ClassifyLine(line string, state *ClassifyState) LineKind {
+	return classifyLine(line, state, "//", "/*", "*/")
+}
+
+// RegexAnalyzer is the fallback LanguageAnalyzer for languages this
+// service has no real parser for. It strips string literals and trailing
+// line comments before matching, so a keyword mentioned inside a string or a
+// line comment isn't mistaken for a declaration or a branch. Text inside
+// block comments is not stripped and is matched like code.
+type RegexAnalyzer struct {
+	FunctionPattern   *regexp.Regexp
+	ClassPattern      *regexp.Regexp
+	DecisionPattern   *regexp.Regexp
+	LineCommentPrefix string
+	BlockCommentStart string
+	BlockCommentEnd   string
+}
+
+func NewPythonAnalyzer() *RegexAnalyzer {
+	return &RegexAnalyzer{
+		FunctionPattern:   regexp.MustCompile(`\bdef\s+\w+`),
+		ClassPattern:      regexp.MustCompile(`\bclass\s+\w+`),
+		DecisionPattern:   regexp.MustCompile(`\b(if|elif|for|while|except|and|or)\b`),
+		LineCommentPrefix: "#",
+	}
+}
+
+func NewRubyAnalyzer() *RegexAnalyzer {
+	return &RegexAnalyzer{
+		FunctionPattern:   regexp.MustCompile(`\bdef\s+\w+`),
+		ClassPattern:      regexp.MustCompile(`\bclass\s+\w+`),
+		DecisionPattern:   regexp.MustCompile(`\b(if|elsif|unless|while|until|case|and|or)\b`),
+		LineCommentPrefix: "#",
+	}
+}
+
+func NewJavaScriptAnalyzer() *RegexAnalyzer {
+	return &RegexAnalyzer{
+		FunctionPattern:   regexp.MustCompile(`\bfunction\b\s*\w*|\b\w+\s*=\s*(\([^)]*\)|\w+)\s*=>`),
+		ClassPattern:      regexp.MustCompile(`\bclass\s+\w+`),
+		DecisionPattern:   regexp.MustCompile(`\b(if|for|while|case|catch)\b|&&|\|\|`),
+		LineCommentPrefix: "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+	}
+}
+
+func (a *RegexAnalyzer) CountFunctions(content string) int {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		count += len(a.FunctionPattern.FindAllString(a.strip(line), -1))
+	}
+	return count
+}
+
+func (a *RegexAnalyzer) CountClasses(content string) int {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		count += len(a.ClassPattern.FindAllString(a.strip(line), -1))
+	}
+	return count
+}
+
+// CyclomaticComplexity can't attribute a decision point to a function
+// without a real parser, so each detected function declaration is charged
+// with the decisions found between it and the next declaration (or end of
+// file). Content with no recognizable function declaration is reported as
+// a single implicit unit so total still reflects its branching.
+func (a *RegexAnalyzer) CyclomaticComplexity(content string) ([]FunctionComplexity, int) {
+	lines := strings.Split(content, "\n")
+
+	var starts []int
+	var names []string
+	for i, line := range lines {
+		if m := a.FunctionPattern.FindString(a.strip(line)); m != "" {
+			starts = append(starts, i)
+			names = append(names, strings.TrimSpace(m))
+		}
+	}
+
+	decisionsBetween := func(from, to int) int {
+		n := 0
+		for l := from; l <= to && l < len(lines); l++ {
+			n += len(a.DecisionPattern.FindAllString(a.strip(lines[l]), -1))
+		}
+		return n
+	}
+
+	if len(starts) == 0 {
+		return nil, 1 + decisionsBetween(0, len(lines)-1)
+	}
+
+	var functions []FunctionComplexity
+	total := 0
+	for idx, start := range starts {
+		end := len(lines) - 1
+		if idx+1 < len(starts) {
+			end = starts[idx+1] - 1
+		}
+
+		complexity := 1 + decisionsBetween(start, end)
+		functions = append(functions, FunctionComplexity{
+			Name:       names[idx],
+			Complexity: complexity,
+			StartLine:  start + 1,
+			EndLine:    end + 1,
+		})
+		total += complexity
+	}
+
+	return functions, total
+}
+
+func (a *RegexAnalyzer) ClassifyLine(line string, state *ClassifyState) LineKind {
+	return classifyLine(line, state, a.LineCommentPrefix, a.BlockCommentStart, a.BlockCommentEnd)
+}
+
+var stringLiteralPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+
+// strip removes string literals and any trailing line comment from line,
+// so the function/class/decision patterns don't match keywords mentioned
+// inside them.
+func (a *RegexAnalyzer) strip(line string) string {
+	line = stringLiteralPattern.ReplaceAllString(line, `""`)
+	if a.LineCommentPrefix != "" {
+		if idx := strings.Index(line, a.LineCommentPrefix); idx >= 0 {
+			line = line[:idx]
+		}
+	}
+	return line
+}
+
+// classifyLine is the shared blank/comment/code classifier used by both
+// GoAnalyzer and RegexAnalyzer; only the comment delimiters differ between
+// languages. blockStart/blockEnd may be empty for languages with no block
+// comment syntax.
+func classifyLine(line string, state *ClassifyState, lineCommentPrefix, blockStart, blockEnd string) LineKind {
+	trimmed := strings.TrimSpace(line)
+
+	if state.InBlockComment {
+		if blockEnd != "" && strings.Contains(trimmed, blockEnd) {
+			state.InBlockComment = false
+		}
+		return LineComment
+	}
+
+	if trimmed == "" {
+		return LineBlank
+	}
+
+	if lineCommentPrefix != "" && strings.HasPrefix(trimmed, lineCommentPrefix) {
+		return LineComment
+	}
+
+	if blockStart != "" && strings.Contains(trimmed, blockStart) {
+		if !(blockEnd != "" && strings.Contains(trimmed, blockEnd)) {
+			state.InBlockComment = true
+		}
+		return LineComment
+	}
+
+	return LineCode
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoAnalyzer_CountFunctions_IgnoresIdentifiersContainingFunc(t *testing.T) {
+	a := NewGoAnalyzer()
+
+	content := `package main
+
+var funcName = "not a function"
+
+func doWork() {}
+
+func helper() {}`
+
+	assert.Equal(t, 2, a.CountFunctions(content))
+}
+
+func TestGoAnalyzer_CountClasses_CountsTypeDeclarations(t *testing.T) {
+	a := NewGoAnalyzer()
+
+	content := `package main
+
+type Widget struct {
+	Name string
+}
+
+type Greeter interface {
+	Greet() string
+}`
+
+	assert.Equal(t, 2, a.CountClasses(content))
+}
+
+func TestGoAnalyzer_CyclomaticComplexity_CountsBranchesPerFunction(t *testing.T) {
+	a := NewGoAnalyzer()
+
+	content := `package main
+
+func simple() {
+	println("hi")
+}
+
+func branchy(a, b int) int {
+	if a > 0 && b > 0 {
+		return a
+	}
+	for i := 0; i < a; i++ {
+		if i == b {
+			return i
+		}
+	}
+	return b
+}`
+
+	functions, total := a.CyclomaticComplexity(content)
+	if assert.Len(t, functions, 2) {
+		assert.Equal(t, "simple", functions[0].Name)
+		assert.Equal(t, 1, functions[0].Complexity)
+
+		assert.Equal(t, "branchy", functions[1].Name)
+		// base(1) + if-with-&&(2) + for(1) + nested if(1) = 5
+		assert.Equal(t, 5, functions[1].Complexity)
+	}
+	assert.Equal(t, 6, total)
+}
+
+func TestGoAnalyzer_CyclomaticComplexity_InvalidSource_ReturnsZero(t *testing.T) {
+	a := NewGoAnalyzer()
+
+	functions, total := a.CyclomaticComplexity("this is not valid go {{{")
+	assert.Nil(t, functions)
+	assert.Equal(t, 0, total)
+}
+
+func TestGoAnalyzer_ClassifyLine_HandlesBlockComments(t *testing.T) {
+	a := NewGoAnalyzer()
+	state := &ClassifyState{}
+
+	assert.Equal(t, LineCode, a.ClassifyLine("x := 1", state))
+	assert.Equal(t, LineBlank, a.ClassifyLine("   ", state))
+	assert.Equal(t, LineComment, a.ClassifyLine("// a comment", state))
+	assert.Equal(t, LineComment, a.ClassifyLine("/* start of block", state))
+	assert.True(t, state.InBlockComment)
+	assert.Equal(t, LineComment, a.ClassifyLine("still in block", state))
+	assert.Equal(t, LineComment, a.ClassifyLine("end of block */", state))
+	assert.False(t, state.InBlockComment)
+	assert.Equal(t, LineCode, a.ClassifyLine("y := 2", state))
+}
+
+func TestPythonAnalyzer_CountFunctions_IgnoresStringsAndComments(t *testing.T) {
+	a := NewPythonAnalyzer()
+
+	content := `# def not_a_function():
+msg = "def also_not_a_function():"
+
+def real_function():
+    pass`
+
+	assert.Equal(t, 1, a.CountFunctions(content))
+}
+
+func TestPythonAnalyzer_CyclomaticComplexity_CountsDecisionKeywords(t *testing.T) {
+	a := NewPythonAnalyzer()
+
+	content := `def branchy(a, b):
+    if a and b:
+        return a
+    elif a:
+        return b
+    return 0`
+
+	functions, total := a.CyclomaticComplexity(content)
+	if assert.Len(t, functions, 1) {
+		// base(1) + if(1) + and(1) + elif(1) = 4
+		assert.Equal(t, 4, functions[0].Complexity)
+	}
+	assert.Equal(t, 4, total)
+}
+
+func TestJavaScriptAnalyzer_CountFunctions_MatchesDeclarationsAndArrows(t *testing.T) {
+	a := NewJavaScriptAnalyzer()
+
+	content := `function namedFn() {}
+const arrowFn = () => {}`
+
+	assert.Equal(t, 2, a.CountFunctions(content))
+}
+
+func TestAnalyzerForLanguage_UnknownFallsBackToRegexAnalyzer(t *testing.T) {
+	a := analyzerForLanguage("unknown")
+	_, ok := a.(*RegexAnalyzer)
+	assert.True(t, ok)
+}
@@ -0,0 +1,319 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DiffOpType identifies what a single DiffOp does to the old/new line
+// sequences.
+type DiffOpType int
+
+const (
+	DiffEqual DiffOpType = iota
+	DiffInsert
+	DiffDelete
+)
+
+// DiffOp is one step of an edit script produced by myersDiff. OldIndex is
+// meaningful for Equal and Delete; NewIndex is meaningful for Equal and
+// Insert.
+type DiffOp struct {
+	Type     DiffOpType
+	OldIndex int
+	NewIndex int
+}
+
+// DiffHunk is a contiguous region of a unified diff: it covers
+// [OldStart, OldStart+OldLines) of the old file and [NewStart,
+// NewStart+NewLines) of the new file (both 0-based), plus the
+// Equal/Insert/Delete ops that make it up, including up to `context`
+// unchanged lines of padding on either side of the actual changes.
+type DiffHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Ops      []DiffOp
+}
+
+// myersDiff computes the shortest edit script turning oldLines into
+// newLines using the Myers O((N+M)D) algorithm: a forward pass builds a
+// trace of the furthest-reaching x for each diagonal k at every edit
+// distance D, then a backward pass walks that trace from (len(old),
+// len(new)) back to (0, 0) to recover the ops in forward order.
+func myersDiff(ctx context.Context, oldLines, newLines []string) ([]DiffOp, error) {
+	if len(oldLines) == 0 && len(newLines) == 0 {
+		return nil, nil
+	}
+
+	trace, err := computeTrace(ctx, oldLines, newLines)
+	if err != nil {
+		return nil, err
+	}
+
+	return backtrackOps(oldLines, newLines, trace), nil
+}
+
+// computeTrace runs the forward D-path loop, snapshotting the V array
+// (indexed by k = x - y, offset by max so negative k is representable)
+// before each depth D is explored. trace[d] is therefore the furthest
+// reach achieved through depth d-1, which is exactly what backtrackOps
+// needs to replay the path that reached depth d.
+func computeTrace(ctx context.Context, oldLines, newLines []string) ([][]int, error) {
+	n, m := len(oldLines), len(newLines)
+	max := n + m
+	offset := max
+
+	v := make([]int, 2*max+1)
+	trace := make([][]int, 0, max+1)
+
+	for d := 0; d <= max; d++ {
+		if d%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && oldLines[x] == newLines[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return trace, nil
+			}
+		}
+	}
+
+	return trace, nil
+}
+
+// backtrackOps replays computeTrace's trace from the end of both sequences
+// back to the start, emitting one DiffOp per step and reversing the result
+// into forward order.
+func backtrackOps(oldLines, newLines []string, trace [][]int) []DiffOp {
+	n, m := len(oldLines), len(newLines)
+	max := n + m
+	offset := max
+
+	x, y := n, m
+	var ops []DiffOp
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, DiffOp{Type: DiffEqual, OldIndex: x - 1, NewIndex: y - 1})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, DiffOp{Type: DiffInsert, NewIndex: y - 1})
+			} else {
+				ops = append(ops, DiffOp{Type: DiffDelete, OldIndex: x - 1})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// diffFromOps turns an edit script into the line-number summary AnalyzeDiff
+// reports: a run of deletes immediately followed (in either order) by
+// inserts is treated as a modification of up to min(deletes, inserts)
+// lines, with any leftover deletes/inserts beyond that reported as pure
+// removals/additions.
+func diffFromOps(ops []DiffOp) *Diff {
+	diff := &Diff{
+		AddedLines:    []int{},
+		RemovedLines:  []int{},
+		ModifiedLines: []int{},
+	}
+
+	i := 0
+	for i < len(ops) {
+		if ops[i].Type == DiffEqual {
+			i++
+			continue
+		}
+
+		runStart := i
+		for i < len(ops) && ops[i].Type != DiffEqual {
+			i++
+		}
+		run := ops[runStart:i]
+
+		var deletes, inserts []DiffOp
+		for _, op := range run {
+			if op.Type == DiffDelete {
+				deletes = append(deletes, op)
+			} else {
+				inserts = append(inserts, op)
+			}
+		}
+
+		paired := minInt(len(deletes), len(inserts))
+		for j := 0; j < paired; j++ {
+			diff.ModifiedLines = append(diff.ModifiedLines, inserts[j].NewIndex)
+		}
+		for j := paired; j < len(deletes); j++ {
+			diff.RemovedLines = append(diff.RemovedLines, deletes[j].OldIndex)
+		}
+		for j := paired; j < len(inserts); j++ {
+			diff.AddedLines = append(diff.AddedLines, inserts[j].NewIndex)
+		}
+	}
+
+	return diff
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// groupHunks collects the non-equal ops into DiffHunks, merging changes
+// that are within 2*contextLines of each other into a single hunk and
+// padding each hunk with up to contextLines unchanged ops on either side,
+// the same way `diff -u`/git decide hunk boundaries.
+func groupHunks(ops []DiffOp, contextLines int) []DiffHunk {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	var changedIdx []int
+	for i, op := range ops {
+		if op.Type != DiffEqual {
+			changedIdx = append(changedIdx, i)
+		}
+	}
+	if len(changedIdx) == 0 {
+		return nil
+	}
+
+	var hunks []DiffHunk
+	start := 0
+	for start < len(changedIdx) {
+		end := start
+		for end+1 < len(changedIdx) && changedIdx[end+1]-changedIdx[end] <= contextLines*2+1 {
+			end++
+		}
+
+		lo := changedIdx[start] - contextLines
+		if lo < 0 {
+			lo = 0
+		}
+		hi := changedIdx[end] + contextLines
+		if hi >= len(ops) {
+			hi = len(ops) - 1
+		}
+
+		hunks = append(hunks, buildHunk(ops[lo:hi+1]))
+		start = end + 1
+	}
+
+	return hunks
+}
+
+// buildHunk derives a DiffHunk's old/new start offsets and line counts from
+// its ops.
+func buildHunk(ops []DiffOp) DiffHunk {
+	hunk := DiffHunk{Ops: ops}
+
+	oldSet, newSet := false, false
+	for _, op := range ops {
+		switch op.Type {
+		case DiffEqual:
+			if !oldSet {
+				hunk.OldStart = op.OldIndex
+				oldSet = true
+			}
+			if !newSet {
+				hunk.NewStart = op.NewIndex
+				newSet = true
+			}
+			hunk.OldLines++
+			hunk.NewLines++
+		case DiffDelete:
+			if !oldSet {
+				hunk.OldStart = op.OldIndex
+				oldSet = true
+			}
+			hunk.OldLines++
+		case DiffInsert:
+			if !newSet {
+				hunk.NewStart = op.NewIndex
+				newSet = true
+			}
+			hunk.NewLines++
+		}
+	}
+
+	return hunk
+}
+
+// UnifiedDiff renders old and new as unified-diff text (the same shape as
+// `diff -u`/`git diff`), with contextLines lines of unchanged context
+// around each changed region.
+func (p *Parser) UnifiedDiff(old, new string, contextLines int) string {
+	oldLines := strings.Split(old, "\n")
+	newLines := strings.Split(new, "\n")
+
+	ops, _ := myersDiff(context.Background(), oldLines, newLines)
+	hunks := groupHunks(ops, contextLines)
+
+	var sb strings.Builder
+	for _, hunk := range hunks {
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", hunk.OldStart+1, hunk.OldLines, hunk.NewStart+1, hunk.NewLines)
+		for _, op := range hunk.Ops {
+			switch op.Type {
+			case DiffEqual:
+				sb.WriteString(" " + oldLines[op.OldIndex] + "\n")
+			case DiffDelete:
+				sb.WriteString("-" + oldLines[op.OldIndex] + "\n")
+			case DiffInsert:
+				sb.WriteString("+" + newLines[op.NewIndex] + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeDiff_TableDriven(t *testing.T) {
+	tests := []struct {
+		name         string
+		old          string
+		new          string
+		wantAdded    []int
+		wantRemoved  []int
+		wantModified []int
+	}{
+		{
+			name:         "pure insert",
+			old:          "a\nb\nc",
+			new:          "a\nb\nx\nc",
+			wantAdded:    []int{2},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+		{
+			name:         "pure delete",
+			old:          "a\nb\nc\nd",
+			new:          "a\nc\nd",
+			wantAdded:    []int{},
+			wantRemoved:  []int{1},
+			wantModified: []int{},
+		},
+		{
+			name:         "trailing insert does not shift earlier lines into modified",
+			old:          "a\nb\nc",
+			new:          "a\nb\nc\nd",
+			wantAdded:    []int{3},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+		{
+			name:         "leading insert shifts everything without marking it modified",
+			old:          "a\nb\nc",
+			new:          "z\na\nb\nc",
+			wantAdded:    []int{0},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+		{
+			name:         "single line modification pairs delete+insert",
+			old:          "a\nb\nc",
+			new:          "a\nB\nc",
+			wantAdded:    []int{},
+			wantRemoved:  []int{},
+			wantModified: []int{1},
+		},
+		{
+			name:         "interleaved edits",
+			old:          "a\nb\nc\nd\ne",
+			new:          "a\nX\nc\nY\nZ\ne",
+			wantAdded:    []int{4},
+			wantRemoved:  []int{},
+			wantModified: []int{1, 3},
+		},
+		{
+			name:         "identical content produces no ops",
+			old:          "a\nb\nc",
+			new:          "a\nb\nc",
+			wantAdded:    []int{},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+		{
+			name:         "trailing newline difference is its own line, not noise",
+			old:          "a\nb",
+			new:          "a\nb\n",
+			wantAdded:    []int{2},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+		{
+			name:         "both empty",
+			old:          "",
+			new:          "",
+			wantAdded:    []int{},
+			wantRemoved:  []int{},
+			wantModified: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			diff, err := p.AnalyzeDiff(tt.old, tt.new)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAdded, diff.AddedLines, "AddedLines")
+			assert.Equal(t, tt.wantRemoved, diff.RemovedLines, "RemovedLines")
+			assert.Equal(t, tt.wantModified, diff.ModifiedLines, "ModifiedLines")
+		})
+	}
+}
+
+func TestMyersDiff_CancelledContext_ReturnsContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := myersDiff(ctx, []string{"a"}, []string{"b"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUnifiedDiff_ContainsHunkHeaderAndChangedLines(t *testing.T) {
+	p := NewParser()
+
+	out := p.UnifiedDiff("a\nb\nc", "a\nB\nc", 1)
+
+	assert.Contains(t, out, "@@ -1,3 +1,3 @@")
+	assert.Contains(t, out, "-b")
+	assert.Contains(t, out, "+B")
+	assert.Contains(t, out, " a")
+	assert.Contains(t, out, " c")
+}
+
+func TestUnifiedDiff_NoChanges_ReturnsEmptyString(t *testing.T) {
+	p := NewParser()
+
+	out := p.UnifiedDiff("a\nb", "a\nb", 3)
+	assert.Empty(t, out)
+}
+
+func TestGroupHunks_MergesCloseChangesIntoOneHunk(t *testing.T) {
+	ops := []DiffOp{
+		{Type: DiffDelete, OldIndex: 0},
+		{Type: DiffEqual, OldIndex: 1, NewIndex: 0},
+		{Type: DiffDelete, OldIndex: 2},
+	}
+
+	hunks := groupHunks(ops, 2)
+	assert.Len(t, hunks, 1)
+}
+
+func TestGroupHunks_SplitsFarApartChanges(t *testing.T) {
+	ops := []DiffOp{
+		{Type: DiffDelete, OldIndex: 0},
+		{Type: DiffEqual, OldIndex: 1, NewIndex: 0},
+		{Type: DiffEqual, OldIndex: 2, NewIndex: 1},
+		{Type: DiffEqual, OldIndex: 3, NewIndex: 2},
+		{Type: DiffEqual, OldIndex: 4, NewIndex: 3},
+		{Type: DiffEqual, OldIndex: 5, NewIndex: 4},
+		{Type: DiffDelete, OldIndex: 6},
+	}
+
+	hunks := groupHunks(ops, 1)
+	assert.Len(t, hunks, 2)
+}
@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// duplicateHashWindow is the number of consecutive normalized tokens a
+	// single Rabin-Karp rolling-hash fingerprint covers.
+	duplicateHashWindow = 5
+	// duplicateBlockWindow caps how many tokens a single reported
+	// DuplicateBlock spans; a longer run of matching fingerprints is split
+	// into consecutive blocks of at most this size.
+	duplicateBlockWindow = 50
+	// duplicateMinRun is the minimum number of consecutive matching
+	// fingerprints required before two locations are reported as
+	// duplicated, rather than a coincidental hash collision.
+	duplicateMinRun = 3
+
+	duplicateHashBase uint64 = 1000003
+)
+
+var tokenPattern = regexp.MustCompile(`\w+`)
+
+// Location identifies a single normalized-token position within a file.
+type Location struct {
+	File       string
+	TokenIndex int
+	Line       int
+}
+
+// DuplicateBlock reports a run of matching normalized-token fingerprints
+// found in two different files.
+type DuplicateBlock struct {
+	FileA      string
+	FileB      string
+	StartLineA int
+	EndLineA   int
+	StartLineB int
+	EndLineB   int
+	Similarity float64
+}
+
+// tokenizedFile is one file reduced to normalized tokens (lowercased
+// identifiers/numbers; punctuation and whitespace dropped), the source
+// line each token came from, and the rolling-hash fingerprint starting at
+// each token position.
+type tokenizedFile struct {
+	path         string
+	tokenLines   []int
+	fingerprints []uint64
+}
+
+func tokenizeFile(file *CodeFile) tokenizedFile {
+	var tokens []uint64
+	var lines []int
+
+	for i, line := range file.Lines {
+		for _, tok := range tokenPattern.FindAllString(line, -1) {
+			tokens = append(tokens, hashToken(strings.ToLower(tok)))
+			lines = append(lines, i+1)
+		}
+	}
+
+	return tokenizedFile{
+		path:         file.Path,
+		tokenLines:   lines,
+		fingerprints: rollingFingerprints(tokens, duplicateHashWindow),
+	}
+}
+
+// hashToken reduces a normalized token to a uint64 via FNV-1a, so
+// rollingFingerprints can treat a token window as a sequence of numbers.
+func hashToken(tok string) uint64 {
+	var h uint64 = 1469598103934665603
+	for i := 0; i < len(tok); i++ {
+		h ^= uint64(tok[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// rollingFingerprints computes a Rabin-Karp rolling hash fingerprint for
+// every window-sized slice of tokens, using uint64 wraparound as the
+// implicit modulus: fingerprints[i] covers tokens[i:i+window].
+func rollingFingerprints(tokens []uint64, window int) []uint64 {
+	if len(tokens) < window {
+		return nil
+	}
+
+	var pow uint64 = 1
+	for i := 0; i < window-1; i++ {
+		pow *= duplicateHashBase
+	}
+
+	fingerprints := make([]uint64, len(tokens)-window+1)
+
+	var hash uint64
+	for i := 0; i < window; i++ {
+		hash = hash*duplicateHashBase + tokens[i]
+	}
+	fingerprints[0] = hash
+
+	for i := 1; i <= len(tokens)-window; i++ {
+		hash = (hash-tokens[i-1]*pow)*duplicateHashBase + tokens[i+window-1]
+		fingerprints[i] = hash
+	}
+
+	return fingerprints
+}
+
+// tokenRange is the token-index span (inclusive) a matching run covers in
+// each of the two files (fileA, fileB) it was found in.
+type tokenRange struct {
+	fileA, fileB string
+	startA, endA int
+	startB, endB int
+}
+
+func (r tokenRange) overlaps(other tokenRange) bool {
+	return r.startA <= other.endA && other.startA <= r.endA
+}
+
+// DetectDuplicates finds duplicated code across files using rolling-hash
+// fingerprints over normalized token windows (see rollingFingerprints).
+// Fingerprints are bucketed by value in a map[uint64][]Location; any two
+// locations from different files sharing duplicateMinRun or more
+// consecutive fingerprints are reported as DuplicateBlocks (split at
+// duplicateBlockWindow tokens apiece), with overlapping ranges for the same
+// file pair deduplicated before returning.
+func DetectDuplicates(files []*CodeFile) []DuplicateBlock {
+	tokenized := make(map[string]tokenizedFile, len(files))
+	buckets := make(map[uint64][]Location)
+
+	for _, f := range files {
+		tf := tokenizeFile(f)
+		tokenized[f.Path] = tf
+		for i, fp := range tf.fingerprints {
+			buckets[fp] = append(buckets[fp], Location{File: f.Path, TokenIndex: i, Line: tf.tokenLines[i]})
+		}
+	}
+
+	// Collect every candidate match first and sort it (instead of relying on
+	// Go's randomized map iteration over buckets) so that which overlapping
+	// candidate "wins" the dedup pass below - and therefore DetectDuplicates'
+	// output - is deterministic and always prefers the earliest-occurring
+	// match for a given file pair.
+	var candidates []tokenRange
+	for _, locs := range buckets {
+		for i := 0; i < len(locs); i++ {
+			for j := i + 1; j < len(locs); j++ {
+				a, b := locs[i], locs[j]
+				if a.File == b.File {
+					continue
+				}
+				if a.File > b.File {
+					a, b = b, a
+				}
+
+				runLen := matchRunLength(tokenized[a.File].fingerprints, a.TokenIndex, tokenized[b.File].fingerprints, b.TokenIndex)
+				if runLen < duplicateMinRun {
+					continue
+				}
+
+				spanTokens := runLen + duplicateHashWindow - 1
+				candidates = append(candidates, tokenRange{
+					fileA: a.File, fileB: b.File,
+					startA: a.TokenIndex, endA: a.TokenIndex + spanTokens - 1,
+					startB: b.TokenIndex, endB: b.TokenIndex + spanTokens - 1,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.fileA != cj.fileA {
+			return ci.fileA < cj.fileA
+		}
+		if ci.fileB != cj.fileB {
+			return ci.fileB < cj.fileB
+		}
+		if ci.startA != cj.startA {
+			return ci.startA < cj.startA
+		}
+		return ci.startB < cj.startB
+	})
+
+	foundRanges := make(map[string][]tokenRange)
+	var blocks []DuplicateBlock
+
+	for _, rng := range candidates {
+		key := rng.fileA + "\x00" + rng.fileB
+		if rangeOverlapsAny(foundRanges[key], rng) {
+			continue
+		}
+		foundRanges[key] = append(foundRanges[key], rng)
+
+		runLen := rng.endA - rng.startA + 1 - duplicateHashWindow + 1
+		blocks = append(blocks, buildDuplicateBlocks(tokenized[rng.fileA], rng.startA, tokenized[rng.fileB], rng.startB, runLen)...)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].FileA != blocks[j].FileA {
+			return blocks[i].FileA < blocks[j].FileA
+		}
+		if blocks[i].FileB != blocks[j].FileB {
+			return blocks[i].FileB < blocks[j].FileB
+		}
+		return blocks[i].StartLineA < blocks[j].StartLineA
+	})
+
+	return blocks
+}
+
+func rangeOverlapsAny(ranges []tokenRange, r tokenRange) bool {
+	for _, existing := range ranges {
+		if existing.overlaps(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRunLength returns how many consecutive fingerprints starting at
+// (fpsA[startA], fpsB[startB]) are equal.
+func matchRunLength(fpsA []uint64, startA int, fpsB []uint64, startB int) int {
+	n := 0
+	for startA+n < len(fpsA) && startB+n < len(fpsB) && fpsA[startA+n] == fpsB[startB+n] {
+		n++
+	}
+	return n
+}
+
+// buildDuplicateBlocks converts a run of runLen matching fingerprints
+// starting at startA/startB into one or more DuplicateBlocks, splitting the
+// run every duplicateBlockWindow tokens so a single long duplicate run
+// doesn't collapse into one block spanning the entire files.
+func buildDuplicateBlocks(a tokenizedFile, startA int, b tokenizedFile, startB int, runLen int) []DuplicateBlock {
+	spanTokens := runLen + duplicateHashWindow - 1
+
+	var blocks []DuplicateBlock
+	for offset := 0; offset < spanTokens; offset += duplicateBlockWindow {
+		end := offset + duplicateBlockWindow
+		if end > spanTokens {
+			end = spanTokens
+		}
+
+		blocks = append(blocks, DuplicateBlock{
+			FileA:      a.path,
+			FileB:      b.path,
+			StartLineA: a.tokenLines[startA+offset],
+			EndLineA:   a.tokenLines[startA+end-1],
+			StartLineB: b.tokenLines[startB+offset],
+			EndLineB:   b.tokenLines[startB+end-1],
+			Similarity: 1.0,
+		})
+	}
+
+	return blocks
+}
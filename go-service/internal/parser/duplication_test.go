@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func duplicatedLines() []string {
+	return []string{
+		"func processOrder(order Order) error {",
+		"if order.Total <= 0 {",
+		"return errors.New(\"invalid total\")",
+		"}",
+		"if order.Customer == \"\" {",
+		"return errors.New(\"missing customer\")",
+		"}",
+		"return nil",
+		"}",
+	}
+}
+
+func TestDetectDuplicates_FindsSharedBlockAcrossFiles(t *testing.T) {
+	files := []*CodeFile{
+		{Path: "a.go", Language: "go", Lines: duplicatedLines()},
+		{Path: "b.go", Language: "go", Lines: duplicatedLines()},
+	}
+
+	blocks := DetectDuplicates(files)
+
+	if assert.NotEmpty(t, blocks) {
+		b := blocks[0]
+		assert.Equal(t, "a.go", b.FileA)
+		assert.Equal(t, "b.go", b.FileB)
+		assert.Equal(t, 1, b.StartLineA)
+		assert.Equal(t, 1, b.StartLineB)
+		assert.Equal(t, 1.0, b.Similarity)
+	}
+}
+
+func TestDetectDuplicates_NoMatchBelowMinRun(t *testing.T) {
+	files := []*CodeFile{
+		{Path: "a.go", Language: "go", Lines: []string{"func a() int { return 1 }"}},
+		{Path: "b.go", Language: "go", Lines: []string{"func b() int { return 2 }"}},
+	}
+
+	blocks := DetectDuplicates(files)
+	assert.Empty(t, blocks)
+}
+
+func TestDetectDuplicates_IgnoresMatchesWithinSameFile(t *testing.T) {
+	lines := append(duplicatedLines(), duplicatedLines()...)
+	files := []*CodeFile{
+		{Path: "a.go", Language: "go", Lines: lines},
+	}
+
+	blocks := DetectDuplicates(files)
+	assert.Empty(t, blocks, "a single file repeating itself isn't a cross-file duplicate")
+}
+
+func TestDetectDuplicates_DeduplicatesOverlappingRanges(t *testing.T) {
+	files := []*CodeFile{
+		{Path: "a.go", Language: "go", Lines: duplicatedLines()},
+		{Path: "b.go", Language: "go", Lines: duplicatedLines()},
+	}
+
+	blocks := DetectDuplicates(files)
+
+	seen := make(map[[2]int]bool)
+	for _, b := range blocks {
+		key := [2]int{b.StartLineA, b.StartLineB}
+		assert.False(t, seen[key], "overlapping duplicate ranges should be deduplicated")
+		seen[key] = true
+	}
+}
+
+func TestRollingFingerprints_MatchesDirectComputation(t *testing.T) {
+	tokens := []uint64{1, 2, 3, 4, 5, 6, 7}
+	fps := rollingFingerprints(tokens, 3)
+
+	assert.Len(t, fps, 5)
+	for i := range fps {
+		var want uint64
+		for j := 0; j < 3; j++ {
+			want = want*duplicateHashBase + tokens[i+j]
+		}
+		assert.Equal(t, want, fps[i])
+	}
+}
+
+func TestRollingFingerprints_ShorterThanWindowReturnsNil(t *testing.T) {
+	assert.Nil(t, rollingFingerprints([]uint64{1, 2}, 5))
+}
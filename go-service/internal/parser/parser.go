@@ -2,9 +2,16 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"strings"
 )
 
+// ctxCheckInterval is how many lines/iterations are processed between
+// context.Context cancellation checks in the Context variants below. Large
+// enough to keep the check from dominating tight loops, small enough that a
+// cancelled request returns promptly even for large inputs.
+const ctxCheckInterval = 2048
+
 type CodeFile struct {
 	Path     string
 	Language string
@@ -26,6 +33,11 @@ type CodeMetrics struct {
 	Complexity   int
 	Functions    int
 	Classes      int
+
+	// FunctionMetrics breaks Complexity down per function/method. It is
+	// only as precise as the LanguageAnalyzer picked for the file's
+	// language (see analyzer.go) - exact for Go, best-effort elsewhere.
+	FunctionMetrics []FunctionComplexity
 }
 
 type Parser struct{}
@@ -35,9 +47,24 @@ func NewParser() *Parser {
 }
 
 func (p *Parser) ParseFile(content string, path string) (*CodeFile, error) {
+	return p.ParseFileContext(context.Background(), content, path)
+}
+
+// ParseFileContext is ParseFile with ctx threaded through so a cancelled or
+// expired request (see middleware.TimeoutMiddleware) can abort parsing of
+// large content instead of running to completion unused.
+func (p *Parser) ParseFileContext(ctx context.Context, content string, path string) (*CodeFile, error) {
 	lines := strings.Split(content, "\n")
 	language := detectLanguage(path)
 
+	for i := range lines {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &CodeFile{
 		Path:     path,
 		Language: language,
@@ -47,108 +74,90 @@ func (p *Parser) ParseFile(content string, path string) (*CodeFile, error) {
 }
 
 func (p *Parser) AnalyzeDiff(oldContent, newContent string) (*Diff, error) {
+	return p.AnalyzeDiffContext(context.Background(), oldContent, newContent)
+}
+
+// AnalyzeDiffContext is AnalyzeDiff with ctx threaded through so a
+// cancelled or expired request can abort the diff early. It computes a
+// proper Myers shortest-edit-script (see diff.go) rather than comparing
+// lines index-by-index, so an insertion or deletion that shifts every
+// subsequent line is reported as exactly that, not as a run of spurious
+// modifications.
+func (p *Parser) AnalyzeDiffContext(ctx context.Context, oldContent, newContent string) (*Diff, error) {
 	oldLines := strings.Split(oldContent, "\n")
 	newLines := strings.Split(newContent, "\n")
 
-	diff := &Diff{
-		AddedLines:    []int{},
-		RemovedLines:  []int{},
-		ModifiedLines: []int{},
+	ops, err := myersDiff(ctx, oldLines, newLines)
+	if err != nil {
+		return nil, err
 	}
 
-	oldMap := make(map[int]string)
-	for i, line := range oldLines {
-		oldMap[i] = strings.TrimSpace(line)
-	}
-
-	newMap := make(map[int]string)
-	for i, line := range newLines {
-		newMap[i] = strings.TrimSpace(line)
-	}
-
-	maxLen := len(oldLines)
-	if len(newLines) > maxLen {
-		maxLen = len(newLines)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		oldLine := ""
-		newLine := ""
-
-		if i < len(oldLines) {
-			oldLine = strings.TrimSpace(oldLines[i])
-		}
-		if i < len(newLines) {
-			newLine = strings.TrimSpace(newLines[i])
-		}
-
-		if oldLine == "" && newLine != "" {
-			diff.AddedLines = append(diff.AddedLines, i)
-		} else if oldLine != "" && newLine == "" {
-			diff.RemovedLines = append(diff.RemovedLines, i)
-		} else if oldLine != "" && newLine != "" && oldLine != newLine {
-			diff.ModifiedLines = append(diff.ModifiedLines, i)
-		}
-	}
+	return diffFromOps(ops), nil
+}
 
-	return diff, nil
+func (p *Parser) CalculateMetrics(content, path string) *CodeMetrics {
+	metrics, _ := p.CalculateMetricsContext(context.Background(), content, path)
+	return metrics
 }
 
-func (p *Parser) CalculateMetrics(content string) *CodeMetrics {
-	scanner := bufio.NewScanner(strings.NewReader(content))
+// CalculateMetricsContext is CalculateMetrics with ctx threaded through so
+// a cancelled or expired request can abort scanning large content early.
+// It returns ctx.Err() if cancelled before scanning completes.
+//
+// Line/function/class/complexity counting is delegated to the
+// LanguageAnalyzer picked for path's language (see analyzer.go), so it
+// understands each language's actual grammar instead of guessing from
+// substrings like "func " or "class " that also match identifiers and
+// comments.
+func (p *Parser) CalculateMetricsContext(ctx context.Context, content, path string) (*CodeMetrics, error) {
+	analyzer := analyzerForLanguage(detectLanguage(path))
 
+	scanner := bufio.NewScanner(strings.NewReader(content))
 	metrics := &CodeMetrics{}
-	inComment := false
-	inMultiLineComment := false
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		metrics.TotalLines++
+	state := &ClassifyState{}
 
-		if line == "" {
-			metrics.BlankLines++
-			continue
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		if lineNum%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return metrics, err
+			}
 		}
 
-		if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "--") {
-			metrics.CommentLines++
-			continue
-		}
-
-		if strings.Contains(line, "/*") {
-			inMultiLineComment = true
-		}
-		if strings.Contains(line, "*/") {
-			inMultiLineComment = false
-			metrics.CommentLines++
-			continue
-		}
+		metrics.TotalLines++
 
-		if inMultiLineComment || inComment {
+		switch analyzer.ClassifyLine(scanner.Text(), state) {
+		case LineBlank:
+			metrics.BlankLines++
+		case LineComment:
 			metrics.CommentLines++
-			continue
+		default:
+			metrics.CodeLines++
 		}
+	}
 
-		metrics.CodeLines++
-
-		if strings.Contains(line, "func ") || strings.Contains(line, "def ") || strings.Contains(line, "function ") {
-			metrics.Functions++
-		}
+	metrics.Functions = analyzer.CountFunctions(content)
+	metrics.Classes = analyzer.CountClasses(content)
 
-		if strings.Contains(line, "class ") || strings.Contains(line, "type ") {
-			metrics.Classes++
-		}
+	functions, total := analyzer.CyclomaticComplexity(content)
+	metrics.FunctionMetrics = functions
+	metrics.Complexity = total
 
-		if strings.Contains(line, "if ") || strings.Contains(line, "for ") || strings.Contains(line, "while ") {
-			metrics.Complexity++
-		}
-	}
+	return metrics, nil
+}
 
-	return metrics
+// DetectLanguage exposes detectLanguage for callers outside this package
+// (see api.Handler) that need to know what language a request will be
+// analyzed as before the parse/metrics call that actually uses it runs.
+func DetectLanguage(path string) string {
+	return detectLanguage(path)
 }
 
 func detectLanguage(path string) string {
-	ext := strings.ToLower(path[strings.LastIndex(path, "."):])
+	dot := strings.LastIndex(path, ".")
+	if dot == -1 {
+		return "unknown"
+	}
+	ext := strings.ToLower(path[dot:])
 
 	langMap := map[string]string{
 		".go":   "go",
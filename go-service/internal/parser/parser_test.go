@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,11 +58,13 @@ func main() {
 	}
 }`
 
-	metrics := p.CalculateMetrics(content)
+	metrics := p.CalculateMetrics(content, "main.go")
 	assert.Greater(t, metrics.TotalLines, 0)
 	assert.Greater(t, metrics.CodeLines, 0)
 	assert.Greater(t, metrics.CommentLines, 0)
 	assert.Greater(t, metrics.Functions, 0)
+	assert.Len(t, metrics.FunctionMetrics, metrics.Functions)
+	assert.Greater(t, metrics.FunctionMetrics[0].Complexity, 1)
 }
 
 func TestDetectLanguage(t *testing.T) {
@@ -81,3 +85,44 @@ func TestDetectLanguage(t *testing.T) {
 		assert.Equal(t, tc.expected, file.Language)
 	}
 }
+
+func largeContent(lines int) string {
+	return strings.Repeat("line\n", lines)
+}
+
+func TestParseFileContext_CancelledBeforeCall_ReturnsContextError(t *testing.T) {
+	p := NewParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.ParseFileContext(ctx, largeContent(ctxCheckInterval*3), "big.go")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAnalyzeDiffContext_CancelledBeforeCall_ReturnsContextError(t *testing.T) {
+	p := NewParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	content := largeContent(ctxCheckInterval * 3)
+	_, err := p.AnalyzeDiffContext(ctx, content, content+"x")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCalculateMetricsContext_CancelledBeforeCall_ReturnsContextError(t *testing.T) {
+	p := NewParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.CalculateMetricsContext(ctx, largeContent(ctxCheckInterval*3), "big.go")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseFileContext_NotCancelled_BehavesLikeParseFile(t *testing.T) {
+	p := NewParser()
+
+	file, err := p.ParseFileContext(context.Background(), "a\nb\nc", "test.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "go", file.Language)
+	assert.Len(t, file.Lines, 3)
+}
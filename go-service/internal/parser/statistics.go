@@ -1,5 +1,7 @@
 package parser
 
+import "strings"
+
 type FileStatistics struct {
 	TotalFiles   int
 	TotalLines   int
@@ -9,6 +11,26 @@ type FileStatistics struct {
 	AverageSize  float64
 	LargestFile  string
 	SmallestFile string
+
+	// PerLanguage holds complexity and comment-density metrics aggregated
+	// across every file of that language (see LanguageAnalyzer).
+	PerLanguage map[string]LanguageStats
+	// Duplicates lists duplicated code blocks detected across files (see
+	// DetectDuplicates).
+	Duplicates []DuplicateBlock
+}
+
+// LanguageStats aggregates per-language cyclomatic complexity and
+// comment-density metrics across every file of that language
+// CalculateFileStats was given.
+type LanguageStats struct {
+	Files             int
+	TotalComplexity   int
+	AverageComplexity float64
+	CodeLines         int
+	CommentLines      int
+	// CommentRatio is CommentLines / CodeLines, or 0 if CodeLines is 0.
+	CommentRatio float64
 }
 
 type StatisticsCalculator struct{}
@@ -17,10 +39,15 @@ func NewStatisticsCalculator() *StatisticsCalculator {
 	return &StatisticsCalculator{}
 }
 
+// CalculateFileStats summarizes files: aggregate size/line counts, a
+// per-language breakdown of complexity and comment density (via
+// analyzerForLanguage, the same LanguageAnalyzer CalculateMetrics uses),
+// and cross-file duplicate code detection (see DetectDuplicates).
 func (sc *StatisticsCalculator) CalculateFileStats(files []*CodeFile) *FileStatistics {
 	if len(files) == 0 {
 		return &FileStatistics{
-			Languages: make(map[string]int),
+			Languages:   make(map[string]int),
+			PerLanguage: make(map[string]LanguageStats),
 		}
 	}
 
@@ -35,11 +62,13 @@ func (sc *StatisticsCalculator) CalculateFileStats(files []*CodeFile) *FileStati
 	totalSize := 0
 	maxSize := files[0].Size
 	minSize := files[0].Size
+	perLanguage := make(map[string]LanguageStats)
 
 	for _, file := range files {
 		totalLines += len(file.Lines)
 		totalSize += file.Size
 		stats.Languages[file.Language]++
+		perLanguage[file.Language] = accumulateLanguageStats(perLanguage[file.Language], file)
 
 		if file.Size > maxSize {
 			maxSize = file.Size
@@ -51,10 +80,109 @@ func (sc *StatisticsCalculator) CalculateFileStats(files []*CodeFile) *FileStati
 		}
 	}
 
+	for language, ls := range perLanguage {
+		if ls.Files > 0 {
+			ls.AverageComplexity = float64(ls.TotalComplexity) / float64(ls.Files)
+		}
+		if ls.CodeLines > 0 {
+			ls.CommentRatio = float64(ls.CommentLines) / float64(ls.CodeLines)
+		}
+		perLanguage[language] = ls
+	}
+
 	stats.TotalLines = totalLines
 	stats.TotalSize = totalSize
 	stats.AverageLines = float64(totalLines) / float64(len(files))
 	stats.AverageSize = float64(totalSize) / float64(len(files))
+	stats.PerLanguage = perLanguage
+	stats.Duplicates = DetectDuplicates(files)
 
 	return stats
 }
+
+// accumulateLanguageStats folds one file's complexity and comment-line
+// counts into ls, the running LanguageStats for file's language.
+func accumulateLanguageStats(ls LanguageStats, file *CodeFile) LanguageStats {
+	analyzer := analyzerForLanguage(file.Language)
+	content := strings.Join(file.Lines, "\n")
+
+	ls.Files++
+
+	_, complexity := analyzer.CyclomaticComplexity(content)
+	ls.TotalComplexity += complexity
+
+	state := &ClassifyState{}
+	for _, line := range file.Lines {
+		switch analyzer.ClassifyLine(line, state) {
+		case LineComment:
+			ls.CommentLines++
+		case LineCode:
+			ls.CodeLines++
+		}
+	}
+
+	return ls
+}
+
+// StreamingStats accumulates a FileStatistics snapshot one file at a time,
+// for callers (see api.Handler.StreamParse) that stream files through the
+// parser as they arrive and can never hold every *CodeFile in memory at
+// once to call StatisticsCalculator.CalculateFileStats directly.
+type StreamingStats struct {
+	totalFiles int
+	totalLines int
+	totalSize  int
+	languages  map[string]int
+
+	largestFile string
+	largestSize int
+
+	smallestFile string
+	smallestSize int
+}
+
+// NewStreamingStats returns an empty StreamingStats ready for Observe calls.
+func NewStreamingStats() *StreamingStats {
+	return &StreamingStats{languages: make(map[string]int)}
+}
+
+// Observe folds one file's line count, size, and language into the running
+// totals.
+func (s *StreamingStats) Observe(path string, lines, size int, language string) {
+	s.totalFiles++
+	s.totalLines += lines
+	s.totalSize += size
+	s.languages[language]++
+
+	if s.totalFiles == 1 || size > s.largestSize {
+		s.largestSize = size
+		s.largestFile = path
+	}
+	if s.totalFiles == 1 || size < s.smallestSize {
+		s.smallestSize = size
+		s.smallestFile = path
+	}
+}
+
+// Snapshot returns the FileStatistics accumulated so far.
+func (s *StreamingStats) Snapshot() *FileStatistics {
+	if s.totalFiles == 0 {
+		return &FileStatistics{Languages: make(map[string]int)}
+	}
+
+	languages := make(map[string]int, len(s.languages))
+	for k, v := range s.languages {
+		languages[k] = v
+	}
+
+	return &FileStatistics{
+		TotalFiles:   s.totalFiles,
+		TotalLines:   s.totalLines,
+		TotalSize:    s.totalSize,
+		Languages:    languages,
+		AverageLines: float64(s.totalLines) / float64(s.totalFiles),
+		AverageSize:  float64(s.totalSize) / float64(s.totalFiles),
+		LargestFile:  s.largestFile,
+		SmallestFile: s.smallestFile,
+	}
+}
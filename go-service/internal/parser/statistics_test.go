@@ -149,3 +149,43 @@ func TestStatisticsCalculator_CalculateFileStats(t *testing.T) {
 		})
 	}
 }
+
+func TestStatisticsCalculator_CalculateFileStats_PerLanguage(t *testing.T) {
+	sc := NewStatisticsCalculator()
+
+	files := []*CodeFile{
+		{
+			Path:     "a.go",
+			Language: "go",
+			Size:     40,
+			Lines: []string{
+				"package demo",
+				"",
+				"// add returns the sum of a and b",
+				"func add(a, b int) int {",
+				"\tif a > 0 {",
+				"\t\treturn a + b",
+				"\t}",
+				"\treturn b",
+				"}",
+			},
+		},
+		{
+			Path:     "b.go",
+			Language: "go",
+			Size:     10,
+			Lines:    []string{"package demo"},
+		},
+	}
+
+	stats := sc.CalculateFileStats(files)
+
+	goStats, ok := stats.PerLanguage["go"]
+	if assert.True(t, ok, "expected a LanguageStats entry for \"go\"") {
+		assert.Equal(t, 2, goStats.Files)
+		assert.Equal(t, 2, goStats.TotalComplexity) // add(): base 1 + 1 for its if; b.go declares no functions
+		assert.Greater(t, goStats.CodeLines, 0)
+		assert.Greater(t, goStats.CommentLines, 0)
+		assert.Greater(t, goStats.CommentRatio, 0.0)
+	}
+}
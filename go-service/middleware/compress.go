@@ -0,0 +1,395 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressConfig controls which responses CompressMiddleware compresses and
+// with what effort.
+type CompressConfig struct {
+	// MinLength is the smallest response body, in bytes, worth compressing.
+	// Bodies shorter than this are served uncompressed, since the framing
+	// overhead of gzip/zstd can exceed the savings.
+	MinLength int
+	// Level is the compression level passed to gzip/flate; zstd maps it to
+	// the nearest EncoderLevel.
+	Level int
+	// ExcludeContentTypes lists Content-Type prefixes that are never
+	// compressed (already-compressed formats, images, etc).
+	ExcludeContentTypes []string
+}
+
+// DefaultCompressConfig returns the compression settings used when none are
+// given explicitly.
+func DefaultCompressConfig() CompressConfig {
+	return CompressConfig{
+		MinLength: 1024,
+		Level:     gzip.DefaultCompression,
+		ExcludeContentTypes: []string{
+			"image/",
+			"video/",
+			"audio/",
+			"application/zip",
+			"application/gzip",
+			"application/x-gzip",
+			"application/zstd",
+		},
+	}
+}
+
+// CompressMiddleware negotiates an encoding from the request's
+// Accept-Encoding header (gzip, deflate, or zstd, picking the
+// highest-q/most-preferred match) and transparently compresses the response
+// body. It buffers the first MinLength bytes of the response before
+// deciding whether compression is worthwhile, so small responses and
+// already-compressed or excluded content types are served as-is. If the
+// handler sets Content-Encoding itself, it is left untouched.
+//
+// Modeled on Echo's compress/decompress middleware pair (see
+// labstack/echo middleware/compress.go and decompress.go).
+func CompressMiddleware(cfg CompressConfig) func(http.Handler) http.Handler {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = 1024
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressResponseWriter{
+				ResponseWriter: w,
+				cfg:            cfg,
+				encoding:       encoding,
+			}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the highest-quality encoding from header that this
+// middleware supports, in order gzip, deflate, zstd when q-values tie.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	type candidate struct {
+		name string
+		q    float64
+	}
+	supported := map[string]bool{"gzip": true, "deflate": true, "zstd": true}
+	preference := map[string]int{"gzip": 0, "deflate": 1, "zstd": 2}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, q := parseEncodingQ(part)
+		if !supported[name] {
+			continue
+		}
+		if q == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{name: name, q: q})
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return preference[candidates[i].name] < preference[candidates[j].name]
+	})
+	return candidates[0].name
+}
+
+func parseEncodingQ(part string) (name string, q float64) {
+	q = 1.0
+	fields := strings.Split(part, ";")
+	name = strings.ToLower(strings.TrimSpace(fields[0]))
+	for _, f := range fields[1:] {
+		f = strings.TrimSpace(f)
+		if v, ok := strings.CutPrefix(f, "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return name, q
+}
+
+// compressResponseWriter buffers the first cfg.MinLength bytes written so it
+// can decide, once it knows the eventual body size and Content-Type,
+// whether to compress at all. Once that decision is made it either streams
+// straight through to the underlying writer or through a compressing
+// encoder for the remainder of the response.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	cfg      CompressConfig
+	encoding string
+
+	statusCode  int
+	wroteHeader bool
+	decided     bool
+	compress    bool
+	buf         []byte
+
+	gzipWriter  *gzip.Writer
+	flateWriter *flate.Writer
+	zstdWriter  *zstd.Encoder
+}
+
+func (cw *compressResponseWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.statusCode = status
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.decided {
+		if cw.compress {
+			return cw.encoderWriter().Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	if cw.Header().Get("Content-Encoding") != "" || cw.excludedContentType() {
+		cw.decide(false)
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) < cw.cfg.MinLength {
+		return len(b), nil
+	}
+
+	cw.decide(true)
+	if _, err := cw.encoderWriter().Write(cw.buf); err != nil {
+		return 0, err
+	}
+	cw.buf = nil
+	return len(b), nil
+}
+
+// Flush satisfies http.Flusher, flushing both the compressor (if any
+// bytes have been buffered into one) and the underlying writer, so
+// streaming handlers see their writes reach the client promptly.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		// A streaming handler is flushing before MinLength bytes have
+		// accumulated; whatever is buffered so far must go out now, so
+		// compression is not worth it.
+		cw.decide(false)
+		if len(cw.buf) > 0 {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+			cw.buf = nil
+		}
+	}
+
+	switch {
+	case cw.gzipWriter != nil:
+		_ = cw.gzipWriter.Flush()
+	case cw.flateWriter != nil:
+		_ = cw.flateWriter.Flush()
+	case cw.zstdWriter != nil:
+		_ = cw.zstdWriter.Flush()
+	}
+
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack satisfies http.Hijacker so upgraded connections (websockets) pass
+// through this middleware untouched.
+func (cw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// Close flushes and releases any compressor, and if the response never
+// reached MinLength bytes, writes out whatever was buffered uncompressed.
+func (cw *compressResponseWriter) Close() {
+	if !cw.decided {
+		cw.decide(false)
+		if len(cw.buf) > 0 {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+			cw.buf = nil
+		}
+		return
+	}
+
+	switch {
+	case cw.gzipWriter != nil:
+		_ = cw.gzipWriter.Close()
+	case cw.flateWriter != nil:
+		_ = cw.flateWriter.Close()
+	case cw.zstdWriter != nil:
+		_ = cw.zstdWriter.Close()
+	}
+}
+
+func (cw *compressResponseWriter) excludedContentType() bool {
+	contentType := cw.Header().Get("Content-Type")
+	if contentType == "" {
+		return false
+	}
+	for _, prefix := range cw.cfg.ExcludeContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decide fixes whether the response will be compressed, writing the status
+// line and (if compressing) the Content-Encoding/Vary headers before any
+// body bytes reach the client.
+func (cw *compressResponseWriter) decide(compress bool) {
+	cw.decided = true
+	cw.compress = compress
+
+	if compress {
+		cw.Header().Set("Content-Encoding", cw.encoding)
+		cw.Header().Add("Vary", "Accept-Encoding")
+		cw.Header().Del("Content-Length")
+	}
+
+	status := cw.statusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressResponseWriter) encoderWriter() io.Writer {
+	switch cw.encoding {
+	case "gzip":
+		if cw.gzipWriter == nil {
+			cw.gzipWriter, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.cfg.Level)
+		}
+		return cw.gzipWriter
+	case "deflate":
+		if cw.flateWriter == nil {
+			cw.flateWriter, _ = flate.NewWriter(cw.ResponseWriter, cw.cfg.Level)
+		}
+		return cw.flateWriter
+	case "zstd":
+		if cw.zstdWriter == nil {
+			cw.zstdWriter, _ = zstd.NewWriter(cw.ResponseWriter, zstd.WithEncoderLevel(zstdLevelFromFlate(cw.cfg.Level)))
+		}
+		return cw.zstdWriter
+	default:
+		return cw.ResponseWriter
+	}
+}
+
+func zstdLevelFromFlate(level int) zstd.EncoderLevel {
+	switch {
+	case level <= 0:
+		return zstd.SpeedDefault
+	case level <= 3:
+		return zstd.SpeedFastest
+	case level <= 6:
+		return zstd.SpeedDefault
+	case level <= 9:
+		return zstd.SpeedBetterCompression
+	default:
+		return zstd.SpeedBestCompression
+	}
+}
+
+// MaxDecompressedBytes bounds how many bytes DecompressMiddleware will
+// inflate a request body to, regardless of what Content-Length claims,
+// guarding against decompression-bomb request bodies. It is larger than
+// MaxContentSize so ValidationMiddleware's own size check is what actually
+// rejects oversized (but non-bomb) bodies; this is strictly a safety net.
+const MaxDecompressedBytes = 10 * MaxContentSize
+
+// DecompressMiddleware transparently decodes a gzip- or zstd-encoded
+// request body before it reaches next (in particular, before
+// ValidationMiddleware reads it), so MaxContentSize is enforced against the
+// decompressed size. Decoding is hard-capped at MaxDecompressedBytes to
+// guard against decompression bombs; a body that would exceed it is
+// rejected with 413 rather than fully inflated into memory.
+func DecompressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+
+		var reader io.Reader
+		switch encoding {
+		case "":
+			next.ServeHTTP(w, r)
+			return
+		case "gzip":
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid gzip request body", http.StatusBadRequest)
+				return
+			}
+			defer gr.Close()
+			reader = gr
+		case "zstd":
+			zr, err := zstd.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid zstd request body", http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+			reader = zr
+		default:
+			http.Error(w, "Unsupported Content-Encoding", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		limited := io.LimitReader(reader, MaxDecompressedBytes+1)
+		decoded, err := io.ReadAll(limited)
+		if err != nil {
+			http.Error(w, "Failed to decompress request body", http.StatusBadRequest)
+			return
+		}
+		if len(decoded) > MaxDecompressedBytes {
+			http.Error(w, "Decompressed request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(decoded))
+		r.ContentLength = int64(len(decoded))
+		r.Header.Del("Content-Encoding")
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressMiddleware_SmallBody_SkipsCompression(t *testing.T) {
+	handler := CompressMiddleware(DefaultCompressConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, `{"ok":true}`, rr.Body.String())
+}
+
+func TestCompressMiddleware_LargeBody_CompressesWithGzip(t *testing.T) {
+	cfg := DefaultCompressConfig()
+	cfg.MinLength = 16
+	payload := strings.Repeat("a", 2048)
+
+	handler := CompressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(payload))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	gr, err := gzip.NewReader(rr.Body)
+	assert.NoError(t, err)
+	decoded, err := io.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(decoded))
+}
+
+func TestCompressMiddleware_PrefersZstdOverGzipByQValue(t *testing.T) {
+	cfg := DefaultCompressConfig()
+	cfg.MinLength = 16
+	payload := strings.Repeat("b", 2048)
+
+	handler := CompressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(payload))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0.5, zstd;q=1.0")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "zstd", rr.Header().Get("Content-Encoding"))
+
+	zr, err := zstd.NewReader(rr.Body)
+	assert.NoError(t, err)
+	defer zr.Close()
+	decoded, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(decoded))
+}
+
+func TestCompressMiddleware_ExcludedContentType_SkipsCompression(t *testing.T) {
+	cfg := DefaultCompressConfig()
+	cfg.MinLength = 1
+	payload := strings.Repeat("c", 2048)
+
+	handler := CompressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(payload))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/image", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, payload, rr.Body.String())
+}
+
+func TestCompressMiddleware_HandlerSetContentEncoding_LeftUntouched(t *testing.T) {
+	cfg := DefaultCompressConfig()
+	cfg.MinLength = 1
+
+	handler := CompressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "identity")
+		_, _ = w.Write([]byte(strings.Repeat("d", 2048)))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "identity", rr.Header().Get("Content-Encoding"))
+}
+
+// TestCompressMiddleware_StreamingHandler_FlushesEachChunk exercises a
+// handler that writes and Flushes several small chunks before the
+// MinLength threshold would otherwise be reached, verifying the
+// compressResponseWriter degrades to pass-through and the underlying
+// http.Flusher still gets called.
+func TestCompressMiddleware_StreamingHandler_FlushesEachChunk(t *testing.T) {
+	cfg := DefaultCompressConfig()
+	cfg.MinLength = 4096
+
+	handler := CompressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		flusher, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		_, _ = w.Write([]byte("chunk1"))
+		flusher.Flush()
+		_, _ = w.Write([]byte("chunk2"))
+		flusher.Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "chunk1chunk2", rr.Body.String())
+}
+
+func TestNegotiateEncoding_NoAcceptableEncoding_ReturnsEmpty(t *testing.T) {
+	assert.Equal(t, "", negotiateEncoding(""))
+	assert.Equal(t, "", negotiateEncoding("br"))
+	assert.Equal(t, "", negotiateEncoding("gzip;q=0"))
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestDecompressMiddleware_GzipBody_DecodedBeforeHandler(t *testing.T) {
+	original := []byte(`{"content":"hello","path":"a.go"}`)
+
+	var gotBody []byte
+	handler := DecompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewReader(gzipBytes(t, original)))
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, original, gotBody)
+}
+
+func TestDecompressMiddleware_NoContentEncoding_PassesThrough(t *testing.T) {
+	var gotBody []byte
+	handler := DecompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader("plain"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "plain", string(gotBody))
+}
+
+func TestDecompressMiddleware_UnsupportedEncoding_Returns415(t *testing.T) {
+	handler := DecompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be reached")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader("x"))
+	req.Header.Set("Content-Encoding", "br")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+}
+
+// TestDecompressMiddleware_GzipBomb_RejectedBeforeFullyInflated builds a
+// small gzip payload that expands to one byte past MaxDecompressedBytes and asserts
+// it is rejected with 413 instead of being read fully into memory.
+func TestDecompressMiddleware_GzipBomb_RejectedBeforeFullyInflated(t *testing.T) {
+	bomb := gzipBytes(t, bytes.Repeat([]byte("0"), MaxDecompressedBytes+1))
+
+	handler := DecompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be reached for an oversized decompressed body")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
@@ -1,10 +1,17 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"net/http"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,33 +19,75 @@ import (
 const (
 	CorrelationIDHeader = "X-Correlation-ID"
 	CorrelationIDKey    = "correlationID"
+	TraceParentHeader   = "traceparent"
+	TraceStateHeader    = "tracestate"
+	TraceContextKey     = "traceContext"
+
+	traceParentVersion = "00"
 )
 
 type TraceData struct {
-	Service       string    `json:"service"`
-	Method        string    `json:"method"`
-	Path          string    `json:"path"`
-	Timestamp     time.Time `json:"timestamp"`
-	CorrelationID string    `json:"correlation_id"`
-	DurationMS    float64   `json:"duration_ms,omitempty"`
-	Status        int       `json:"status,omitempty"`
-	Error         string    `json:"error,omitempty"`
+	Service       string            `json:"service"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	Timestamp     time.Time         `json:"timestamp"`
+	CorrelationID string            `json:"correlation_id"`
+	DurationMS    float64           `json:"duration_ms,omitempty"`
+	Status        int               `json:"status,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	SpanID        string            `json:"span_id,omitempty"`
+	StartTime     time.Time         `json:"start_time,omitempty"`
+	EndTime       time.Time         `json:"end_time,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+}
+
+// TraceContext carries the W3C trace/span identifiers for a single request
+// through context.Context so downstream HTTP calls can propagate them.
+type TraceContext struct {
+	TraceID      string
+	ParentSpanID string
+	SpanID       string
+	Flags        string
+	TraceState   string
 }
 
 var (
 	traceStorage = make(map[string][]TraceData)
 	traceMutex   sync.RWMutex
 	validIDRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+	traceParentRegex = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
 )
 
 func CorrelationIDMiddleware(next http.Handler) http.Handler {
+	return traceMiddleware(next, nil)
+}
+
+// traceMiddleware is the shared implementation behind CorrelationIDMiddleware
+// and Tracer.Middleware: it resolves the trace context, records the
+// completed span to traceStorage (so GetTraces/GetAllTraces keep working),
+// and, if onSpan is non-nil, hands the same span to it afterwards so a
+// Tracer can additionally ship a sampled subset to an external collector.
+func traceMiddleware(next http.Handler, onSpan func(TraceContext, TraceData)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		correlationID := extractOrGenerateCorrelationID(r)
+		tc, correlationID := resolveTraceContext(r)
 
 		ctx := context.WithValue(r.Context(), CorrelationIDKey, correlationID)
+		ctx = context.WithValue(ctx, TraceContextKey, tc)
 		r = r.WithContext(ctx)
 
 		w.Header().Set(CorrelationIDHeader, correlationID)
+		if tc.ParentSpanID != "" {
+			// A valid incoming traceparent is echoed back unchanged.
+			w.Header().Set(TraceParentHeader, buildTraceParent(tc.TraceID, tc.ParentSpanID, tc.Flags))
+		} else {
+			w.Header().Set(TraceParentHeader, buildTraceParent(tc.TraceID, tc.SpanID, tc.Flags))
+		}
+		if tc.TraceState != "" {
+			w.Header().Set(TraceStateHeader, tc.TraceState)
+		}
 
 		startTime := time.Now()
 		traceData := TraceData{
@@ -47,20 +96,137 @@ func CorrelationIDMiddleware(next http.Handler) http.Handler {
 			Path:          r.URL.Path,
 			Timestamp:     startTime,
 			CorrelationID: correlationID,
+			TraceID:       tc.TraceID,
+			ParentSpanID:  tc.ParentSpanID,
+			SpanID:        tc.SpanID,
+			StartTime:     startTime,
 		}
 
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		next.ServeHTTP(wrapped, r)
 
-		duration := time.Since(startTime).Milliseconds()
-		traceData.DurationMS = float64(duration)
+		endTime := time.Now()
+		traceData.EndTime = endTime
+		traceData.DurationMS = float64(endTime.Sub(startTime).Milliseconds())
 		traceData.Status = wrapped.statusCode
 
 		storeTrace(correlationID, traceData)
+
+		if onSpan != nil {
+			onSpan(tc, traceData)
+		}
 	})
 }
 
+// resolveTraceContext derives the trace/span identifiers for the current
+// request. A well-formed traceparent header always wins; its trace-id
+// becomes the backward-compatible CorrelationIDHeader value. Legacy clients
+// that only send X-Correlation-ID keep getting that exact value echoed back,
+// with a freshly generated trace/span pair used for the new trace pipeline.
+func resolveTraceContext(r *http.Request) (TraceContext, string) {
+	return resolveTraceContextFromHeader(r.Header.Get)
+}
+
+// ResolveTraceContextFromHeader is resolveTraceContext generalized to any
+// framework's header accessor (net/http's r.Header.Get, Gin's
+// ctx.GetHeader, Fiber's ctx.Get, ...), so adapters that aren't built
+// directly on net/http - see the tracing package - can reuse the same W3C
+// traceparent resolution logic instead of reimplementing it.
+func ResolveTraceContextFromHeader(getHeader func(string) string) (TraceContext, string) {
+	return resolveTraceContextFromHeader(getHeader)
+}
+
+func resolveTraceContextFromHeader(getHeader func(string) string) (TraceContext, string) {
+	if traceID, parentSpanID, flags, ok := parseTraceParent(getHeader(TraceParentHeader)); ok {
+		return TraceContext{
+			TraceID:      traceID,
+			ParentSpanID: parentSpanID,
+			SpanID:       generateSpanID(),
+			Flags:        flags,
+			TraceState:   getHeader(TraceStateHeader),
+		}, traceID
+	}
+
+	if existingID := getHeader(CorrelationIDHeader); existingID != "" && isValidCorrelationID(existingID) {
+		return TraceContext{
+			TraceID: generateTraceID(),
+			SpanID:  generateSpanID(),
+			Flags:   "01",
+		}, existingID
+	}
+
+	traceID := generateTraceID()
+	return TraceContext{
+		TraceID: traceID,
+		SpanID:  generateSpanID(),
+		Flags:   "01",
+	}, traceID
+}
+
+// parseTraceParent parses a W3C traceparent header of the form
+// "00-<32 hex trace-id>-<16 hex span-id>-<2 hex flags>". It rejects
+// all-zero trace/span ids, which the spec treats as invalid.
+func parseTraceParent(header string) (traceID, parentSpanID, flags string, ok bool) {
+	if header == "" {
+		return "", "", "", false
+	}
+
+	m := traceParentRegex.FindStringSubmatch(header)
+	if m == nil {
+		return "", "", "", false
+	}
+
+	traceID, spanID, flags := m[1], m[2], m[3]
+	if traceID == strings.Repeat("0", 32) || spanID == strings.Repeat("0", 16) {
+		return "", "", "", false
+	}
+
+	return traceID, spanID, flags, true
+}
+
+func buildTraceParent(traceID, spanID, flags string) string {
+	if flags == "" {
+		flags = "01"
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", traceParentVersion, traceID, spanID, flags)
+}
+
+// BuildTraceParent exposes buildTraceParent for adapters (see the tracing
+// package) that assemble the traceparent response header themselves.
+func BuildTraceParent(traceID, spanID, flags string) string {
+	return buildTraceParent(traceID, spanID, flags)
+}
+
+// GenerateSpanID exposes generateSpanID for adapters that mint their own
+// child spans outside of traceMiddleware.
+func GenerateSpanID() string {
+	return generateSpanID()
+}
+
+func generateTraceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+func generateSpanID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// ContextTraceContext returns the TraceContext stored on ctx by
+// CorrelationIDMiddleware, if any.
+func ContextTraceContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(TraceContextKey).(TraceContext)
+	return tc, ok
+}
+
 func extractOrGenerateCorrelationID(r *http.Request) string {
 	existingID := r.Header.Get(CorrelationIDHeader)
 	if existingID != "" && isValidCorrelationID(existingID) {
@@ -110,6 +276,12 @@ func storeTrace(correlationID string, trace TraceData) {
 	cleanupOldTraces()
 }
 
+// StoreTrace exposes storeTrace for adapters (see the tracing package)
+// that record spans without going through traceMiddleware.
+func StoreTrace(correlationID string, trace TraceData) {
+	storeTrace(correlationID, trace)
+}
+
 func cleanupOldTraces() {
 	cutoffTime := time.Now().Add(-1 * time.Hour)
 	for id, traces := range traceStorage {
@@ -155,3 +327,462 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// TraceExporter ships completed spans to an external collector.
+type TraceExporter interface {
+	ExportSpans(ctx context.Context, spans []TraceData) error
+}
+
+// BatchExporter buffers spans in memory and flushes them to an underlying
+// TraceExporter either when the batch reaches maxBatchSize or when
+// flushInterval elapses, whichever comes first. The queue is bounded; once
+// full, new spans are dropped and counted rather than blocking the request
+// path.
+type BatchExporter struct {
+	exporter      TraceExporter
+	maxBatchSize  int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	pending []TraceData
+	queued  chan TraceData
+
+	dropped uint64
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func NewBatchExporter(exporter TraceExporter, queueSize, maxBatchSize int, flushInterval time.Duration) *BatchExporter {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	if maxBatchSize <= 0 {
+		maxBatchSize = 100
+	}
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	b := &BatchExporter{
+		exporter:      exporter,
+		maxBatchSize:  maxBatchSize,
+		flushInterval: flushInterval,
+		queued:        make(chan TraceData, queueSize),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+
+	go b.run()
+	return b
+}
+
+// Enqueue adds a span to the batch. It never blocks: if the queue is full
+// the span is dropped and DroppedCount is incremented.
+func (b *BatchExporter) Enqueue(span TraceData) {
+	select {
+	case b.queued <- span:
+	default:
+		b.mu.Lock()
+		b.dropped++
+		b.mu.Unlock()
+	}
+}
+
+func (b *BatchExporter) DroppedCount() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+func (b *BatchExporter) run() {
+	defer close(b.doneCh)
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case span := <-b.queued:
+			b.pending = append(b.pending, span)
+			if len(b.pending) >= b.maxBatchSize {
+				b.flush()
+			}
+		case <-ticker.C:
+			b.flush()
+		case <-b.stopCh:
+			b.drain()
+			b.flush()
+			return
+		}
+	}
+}
+
+func (b *BatchExporter) drain() {
+	for {
+		select {
+		case span := <-b.queued:
+			b.pending = append(b.pending, span)
+		default:
+			return
+		}
+	}
+}
+
+func (b *BatchExporter) flush() {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	batch := b.pending
+	b.pending = nil
+
+	_ = b.exporter.ExportSpans(context.Background(), batch)
+}
+
+// Shutdown stops the background flush loop after draining and exporting any
+// remaining spans.
+func (b *BatchExporter) Shutdown(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.stopCh) })
+
+	select {
+	case <-b.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OTLPExporter sends spans as an OTLP-over-HTTP request. Collectors that
+// speak the OTLP JSON encoding (rather than protobuf) can consume this
+// directly; it mirrors the shape of ExportTraceServiceRequest closely enough
+// for ingestion without pulling in the OTLP protobuf definitions.
+type OTLPExporter struct {
+	Endpoint    string
+	ServiceName string
+	Client      *http.Client
+}
+
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		Endpoint:    endpoint,
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource `json:"resource"`
+	ScopeSpans []otlpScope  `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	StartTimeUnixNano int64          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   int64          `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+}
+
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []TraceData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		attrs := []otlpKeyValue{
+			{Key: "http.method", Value: s.Method},
+			{Key: "http.path", Value: s.Path},
+			{Key: "http.status_code", Value: fmt.Sprintf("%d", s.Status)},
+		}
+		if s.Error != "" {
+			attrs = append(attrs, otlpKeyValue{Key: "error", Value: s.Error})
+		}
+		for k, v := range s.Attributes {
+			attrs = append(attrs, otlpKeyValue{Key: k, Value: v})
+		}
+
+		otlpSpans = append(otlpSpans, otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              fmt.Sprintf("%s %s", s.Method, s.Path),
+			StartTimeUnixNano: s.StartTime.UnixNano(),
+			EndTimeUnixNano:   s.EndTime.UnixNano(),
+			Attributes:        attrs,
+		})
+	}
+
+	body := otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{{Key: "service.name", Value: e.ServiceName}},
+				},
+				ScopeSpans: []otlpScope{{Spans: otlpSpans}},
+			},
+		},
+	}
+
+	return postJSON(ctx, e.Client, e.Endpoint, body)
+}
+
+// JaegerExporter sends spans in a simplified Jaeger-collector-compatible
+// batch shape over HTTP.
+type JaegerExporter struct {
+	CollectorURL string
+	ServiceName  string
+	Client       *http.Client
+}
+
+func NewJaegerExporter(collectorURL, serviceName string) *JaegerExporter {
+	return &JaegerExporter{
+		CollectorURL: collectorURL,
+		ServiceName:  serviceName,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type jaegerBatch struct {
+	Process jaegerProcess `json:"process"`
+	Spans   []jaegerSpan  `json:"spans"`
+}
+
+type jaegerProcess struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	ParentSpanID  string            `json:"parentSpanID,omitempty"`
+	OperationName string            `json:"operationName"`
+	StartTime     int64             `json:"startTime"`
+	Duration      int64             `json:"duration"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+func (e *JaegerExporter) ExportSpans(ctx context.Context, spans []TraceData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	jaegerSpans := make([]jaegerSpan, 0, len(spans))
+	for _, s := range spans {
+		tags := map[string]string{
+			"http.method":      s.Method,
+			"http.path":        s.Path,
+			"http.status_code": fmt.Sprintf("%d", s.Status),
+		}
+		if s.Error != "" {
+			tags["error"] = s.Error
+		}
+		for k, v := range s.Attributes {
+			tags[k] = v
+		}
+
+		jaegerSpans = append(jaegerSpans, jaegerSpan{
+			TraceID:       s.TraceID,
+			SpanID:        s.SpanID,
+			ParentSpanID:  s.ParentSpanID,
+			OperationName: fmt.Sprintf("%s %s", s.Method, s.Path),
+			StartTime:     s.StartTime.UnixMicro(),
+			Duration:      s.EndTime.Sub(s.StartTime).Microseconds(),
+			Tags:          tags,
+		})
+	}
+
+	batch := jaegerBatch{
+		Process: jaegerProcess{ServiceName: e.ServiceName},
+		Spans:   jaegerSpans,
+	}
+
+	return postJSON(ctx, e.Client, e.CollectorURL, batch)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("trace export failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// TracerConfig configures a Tracer: where spans are shipped, how this
+// service identifies itself, what fraction of requests are sampled for
+// export, and how the background batching exporter behaves.
+type TracerConfig struct {
+	// CollectorURL is the OTLP/HTTP or Jaeger-collector endpoint spans are
+	// POSTed to.
+	CollectorURL string
+	// ServiceName tags every exported span; defaults to "go-parser".
+	ServiceName string
+	// Protocol selects the wire format: "otlp" (default) or "jaeger".
+	Protocol string
+	// SampleRate is the fraction of traces, in [0, 1], that get shipped to
+	// CollectorURL. Every span in traceStorage is still recorded regardless
+	// of sampling, so GetTraces/GetAllTraces are unaffected.
+	SampleRate float64
+	// FlushInterval is how often the batching exporter flushes even if
+	// MaxBatchSize hasn't been reached.
+	FlushInterval time.Duration
+	// QueueSize bounds how many spans may be buffered awaiting export.
+	QueueSize int
+	// MaxBatchSize is the largest batch shipped in a single export call.
+	MaxBatchSize int
+}
+
+// DefaultTracerConfig returns a TracerConfig that samples and exports every
+// request.
+func DefaultTracerConfig() TracerConfig {
+	return TracerConfig{
+		ServiceName:   "go-parser",
+		Protocol:      "otlp",
+		SampleRate:    1.0,
+		FlushInterval: 5 * time.Second,
+		QueueSize:     1024,
+		MaxBatchSize:  100,
+	}
+}
+
+// Sampler decides whether the span for a given trace ID should be shipped
+// to the collector.
+type Sampler func(traceID string) bool
+
+// NewRatioSampler returns a Sampler that exports approximately the given
+// fraction of traces. Sampling is deterministic per trace ID (via an FNV
+// hash), so every span belonging to the same trace is sampled the same
+// way.
+func NewRatioSampler(rate float64) Sampler {
+	if rate <= 0 {
+		return func(string) bool { return false }
+	}
+	if rate >= 1 {
+		return func(string) bool { return true }
+	}
+
+	threshold := uint64(rate * float64(math.MaxUint64))
+	return func(traceID string) bool {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(traceID))
+		return h.Sum64() < threshold
+	}
+}
+
+// Tracer turns CorrelationIDMiddleware into a full tracing pipeline: every
+// request is still recorded to traceStorage as before, and a Sampler-chosen
+// subset of spans is additionally shipped to CollectorURL via a background
+// BatchExporter.
+type Tracer struct {
+	sampler  Sampler
+	exporter *BatchExporter
+}
+
+// NewTracer builds a Tracer from cfg, choosing an OTLP or Jaeger exporter
+// per cfg.Protocol and starting its background batching goroutine.
+func NewTracer(cfg TracerConfig) *Tracer {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "go-parser"
+	}
+
+	var exporter TraceExporter
+	switch cfg.Protocol {
+	case "jaeger":
+		exporter = NewJaegerExporter(cfg.CollectorURL, serviceName)
+	default:
+		exporter = NewOTLPExporter(cfg.CollectorURL, serviceName)
+	}
+
+	return &Tracer{
+		sampler:  NewRatioSampler(cfg.SampleRate),
+		exporter: NewBatchExporter(exporter, cfg.QueueSize, cfg.MaxBatchSize, cfg.FlushInterval),
+	}
+}
+
+// Middleware wraps next exactly like CorrelationIDMiddleware, additionally
+// enqueuing each sampled span onto t's batching exporter for delivery to
+// CollectorURL.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	return traceMiddleware(next, t.RecordSampled)
+}
+
+// RecordSampled ships span to t's exporter if tc's trace is chosen by t's
+// Sampler. Tracer.Middleware uses this as traceMiddleware's onSpan
+// callback; adapters that don't run through traceMiddleware at all (see
+// the tracing package) call it directly once they've finished their own
+// span.
+func (t *Tracer) RecordSampled(tc TraceContext, span TraceData) {
+	if t.sampler(tc.TraceID) {
+		t.exporter.Enqueue(span)
+	}
+}
+
+// Shutdown stops t's background exporter after draining and exporting any
+// buffered spans.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.exporter.Shutdown(ctx)
+}
+
+// TracingRoundTripper propagates the request's TraceContext to downstream
+// HTTP calls by minting a child span id and forwarding it as a traceparent
+// header, so nested http.Client calls stay attached to the same trace.
+type TracingRoundTripper struct {
+	Next http.RoundTripper
+}
+
+func NewTracingRoundTripper(next http.RoundTripper) *TracingRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &TracingRoundTripper{Next: next}
+}
+
+func (t *TracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if tc, ok := ContextTraceContext(req.Context()); ok {
+		childSpanID := generateSpanID()
+		req = req.Clone(req.Context())
+		req.Header.Set(TraceParentHeader, buildTraceParent(tc.TraceID, childSpanID, tc.Flags))
+		if tc.TraceState != "" {
+			req.Header.Set(TraceStateHeader, tc.TraceState)
+		}
+	}
+	return t.Next.RoundTrip(req)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
@@ -321,3 +322,223 @@ func TestCorrelationIDMiddleware_DefaultStatusWhenNoWriteHeader(t *testing.T) {
 		assert.Equal(t, http.StatusOK, traces[0].Status)
 	}
 }
+
+func TestParseTraceParent_ValidAndInvalid(t *testing.T) {
+	traceID, spanID, flags, ok := parseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+	assert.Equal(t, "01", flags)
+
+	tests := []string{
+		"",
+		"not-a-traceparent",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-tooshort-00f067aa0ba902b7-01",
+	}
+	for _, tt := range tests {
+		_, _, _, ok := parseTraceParent(tt)
+		assert.False(t, ok, "expected %q to be invalid", tt)
+	}
+}
+
+func TestCorrelationIDMiddleware_ValidTraceParentEchoedUnchanged(t *testing.T) {
+	resetTraces(t)
+
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := CorrelationIDMiddleware(next)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/trace", nil)
+	req.Header.Set(TraceParentHeader, incoming)
+
+	handler.ServeHTTP(rr, req)
+
+	resp := rr.Result()
+	outgoing := resp.Header.Get(TraceParentHeader)
+	outTraceID, outSpanID, _, ok := parseTraceParent(outgoing)
+	assert.True(t, ok)
+
+	// The incoming traceparent comes back unchanged.
+	assert.Equal(t, incoming, outgoing)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", outTraceID)
+	assert.Equal(t, "00f067aa0ba902b7", outSpanID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", resp.Header.Get(CorrelationIDHeader))
+}
+
+func TestCorrelationIDMiddleware_MalformedTraceParentReplaced(t *testing.T) {
+	resetTraces(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := CorrelationIDMiddleware(next)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/trace", nil)
+	req.Header.Set(TraceParentHeader, "garbage-traceparent")
+
+	handler.ServeHTTP(rr, req)
+
+	resp := rr.Result()
+	_, _, _, ok := parseTraceParent(resp.Header.Get(TraceParentHeader))
+	assert.True(t, ok, "a fresh valid traceparent should be generated")
+}
+
+func TestTracingRoundTripper_PreservesTraceIDMintsChildSpan(t *testing.T) {
+	parentTC := TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Flags:   "01",
+	}
+
+	var seenTraceParent string
+	rt := NewTracingRoundTripper(http.RoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		seenTraceParent = r.Header.Get(TraceParentHeader)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header)}, nil
+	})))
+
+	ctx := context.WithValue(context.Background(), TraceContextKey, parentTC)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/downstream", nil)
+
+	_, err := rt.RoundTrip(req)
+	assert.NoError(t, err)
+
+	traceID, spanID, _, ok := parseTraceParent(seenTraceParent)
+	assert.True(t, ok)
+	assert.Equal(t, parentTC.TraceID, traceID)
+	assert.NotEqual(t, parentTC.SpanID, spanID, "downstream hop should get its own child span id")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewRatioSampler_ZeroAndOneAreUnconditional(t *testing.T) {
+	never := NewRatioSampler(0)
+	always := NewRatioSampler(1)
+
+	for _, traceID := range []string{"a", "b", "4bf92f3577b34da6a3ce929d0e0e4736"} {
+		assert.False(t, never(traceID))
+		assert.True(t, always(traceID))
+	}
+}
+
+func TestNewRatioSampler_SameTraceIDAlwaysAgrees(t *testing.T) {
+	sampler := NewRatioSampler(0.5)
+
+	first := sampler("4bf92f3577b34da6a3ce929d0e0e4736")
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, sampler("4bf92f3577b34da6a3ce929d0e0e4736"))
+	}
+}
+
+func TestTracer_Middleware_SamplesAndExports(t *testing.T) {
+	resetTraces(t)
+
+	exported := make(chan TraceData, 1)
+	stub := exporterFunc(func(ctx context.Context, spans []TraceData) error {
+		for _, s := range spans {
+			exported <- s
+		}
+		return nil
+	})
+
+	tr := &Tracer{
+		sampler:  NewRatioSampler(1),
+		exporter: NewBatchExporter(stub, 10, 1, time.Millisecond),
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	tr.Middleware(next).ServeHTTP(rr, req)
+
+	select {
+	case span := <-exported:
+		assert.Equal(t, "/traced", span.Path)
+		assert.Equal(t, http.StatusOK, span.Status)
+	case <-time.After(time.Second):
+		t.Fatal("span was never exported")
+	}
+
+	// The in-process pipeline still works regardless of export.
+	correlationID := rr.Result().Header.Get(CorrelationIDHeader)
+	assert.Len(t, GetTraces(correlationID), 1)
+}
+
+func TestTracer_Middleware_UnsampledSkipsExport(t *testing.T) {
+	resetTraces(t)
+
+	exported := make(chan TraceData, 1)
+	stub := exporterFunc(func(ctx context.Context, spans []TraceData) error {
+		for _, s := range spans {
+			exported <- s
+		}
+		return nil
+	})
+
+	tr := &Tracer{
+		sampler:  NewRatioSampler(0),
+		exporter: NewBatchExporter(stub, 10, 1, 5*time.Millisecond),
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/untraced", nil)
+	tr.Middleware(next).ServeHTTP(rr, req)
+
+	select {
+	case span := <-exported:
+		t.Fatalf("unsampled span should not have been exported: %+v", span)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestNewTracer_DefaultsServiceNameAndBuildsOTLPExporter(t *testing.T) {
+	tr := NewTracer(TracerConfig{CollectorURL: "http://collector.local"})
+	assert.NotNil(t, tr)
+	assert.NotNil(t, tr.sampler)
+	assert.NoError(t, tr.Shutdown(context.Background()))
+}
+
+func TestResolveTraceContextFromHeader_MatchesNetHTTPResolution(t *testing.T) {
+	headers := map[string]string{
+		TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	tc, correlationID := ResolveTraceContextFromHeader(func(key string) string { return headers[key] })
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", tc.ParentSpanID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", correlationID)
+}
+
+func TestResolveTraceContextFromHeader_NoHeaders_GeneratesFreshTrace(t *testing.T) {
+	tc, correlationID := ResolveTraceContextFromHeader(func(string) string { return "" })
+
+	assert.NotEmpty(t, tc.TraceID)
+	assert.NotEmpty(t, tc.SpanID)
+	assert.Equal(t, tc.TraceID, correlationID)
+}
+
+type exporterFunc func(ctx context.Context, spans []TraceData) error
+
+func (f exporterFunc) ExportSpans(ctx context.Context, spans []TraceData) error {
+	return f(ctx, spans)
+}
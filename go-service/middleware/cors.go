@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig controls how CORSMiddleware decides which origins, methods,
+// and headers are allowed, and how preflight responses are shaped.
+type CORSConfig struct {
+	// AllowOrigins is the static allowlist of origins. A single "*" entry
+	// allows any origin; combined with AllowCredentials, the request's own
+	// origin is echoed instead of "*", since the Fetch spec forbids a
+	// wildcard with credentials.
+	AllowOrigins []string
+	// AllowOriginFunc, if set, decides every origin on its own;
+	// AllowOrigins is then ignored.
+	AllowOriginFunc  func(origin string) bool
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int // seconds; 0 omits Access-Control-Max-Age
+}
+
+// DefaultCORSConfig returns a CORSConfig with sensible defaults for this
+// service, including the correlation ID header in the exposed-headers list
+// so browser clients can read it via fetch/XHR.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowHeaders:  []string{"Content-Type", "Authorization"},
+		ExposeHeaders: []string{CorrelationIDHeader},
+		MaxAge:        600,
+	}
+}
+
+// CORSMiddleware handles CORS preflight (OPTIONS) requests and injects the
+// appropriate Access-Control-* headers on simple/actual requests whose
+// Origin is allowed.
+func CORSMiddleware(cfg CORSConfig) func(http.Handler) http.Handler {
+	exposeHeaders := ensureCorrelationHeaderExposed(cfg.ExposeHeaders)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowedOrigin, allowed := resolveAllowedOrigin(cfg, origin)
+			if !allowed {
+				if r.Method == http.MethodOptions {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("Access-Control-Allow-Origin", allowedOrigin)
+			if allowedOrigin != "*" {
+				header.Add("Vary", "Origin")
+			}
+			if cfg.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if len(cfg.AllowMethods) > 0 {
+					header.Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowMethods, ", "))
+				}
+
+				requestedHeaders := cfg.AllowHeaders
+				if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" && len(requestedHeaders) == 0 {
+					requestedHeaders = []string{reqHeaders}
+				}
+				if len(requestedHeaders) > 0 {
+					header.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+				}
+
+				if cfg.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if len(exposeHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(exposeHeaders, ", "))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func resolveAllowedOrigin(cfg CORSConfig, origin string) (string, bool) {
+	if cfg.AllowOriginFunc != nil {
+		if cfg.AllowOriginFunc(origin) {
+			return origin, true
+		}
+		return "", false
+	}
+
+	for _, allowed := range cfg.AllowOrigins {
+		if allowed == "*" {
+			if cfg.AllowCredentials {
+				// Credentialed requests can never use the wildcard; echo the
+				// specific origin instead so the combination stays spec-legal.
+				return origin, true
+			}
+			return "*", true
+		}
+		if allowed == origin {
+			return origin, true
+		}
+	}
+
+	return "", false
+}
+
+func ensureCorrelationHeaderExposed(headers []string) []string {
+	for _, h := range headers {
+		if strings.EqualFold(h, CorrelationIDHeader) {
+			return headers
+		}
+	}
+	return append(append([]string{}, headers...), CorrelationIDHeader)
+}
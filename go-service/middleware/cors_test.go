@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func passthroughHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestCORSMiddleware_Preflight_AllowedOrigin(t *testing.T) {
+	cfg := CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		AllowMethods:  []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:  []string{"Content-Type"},
+		ExposeHeaders: []string{"X-Custom"},
+		MaxAge:        300,
+	}
+
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodOptions, "/parse", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", rr.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "300", rr.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSMiddleware_Preflight_DisallowedOrigin(t *testing.T) {
+	cfg := CORSConfig{AllowOrigins: []string{"https://app.example.com"}}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodOptions, "/parse", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_SimpleRequest_InjectsHeaders(t *testing.T) {
+	cfg := CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"X-Custom"},
+	}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Expose-Headers"), "X-Custom")
+	assert.Contains(t, rr.Header().Get("Access-Control-Expose-Headers"), CorrelationIDHeader)
+}
+
+func TestCORSMiddleware_SimpleRequest_DisallowedOrigin_NoHeaders(t *testing.T) {
+	cfg := CORSConfig{AllowOrigins: []string{"https://app.example.com"}}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_NoOriginHeader_PassesThroughUntouched(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_WildcardOrigin(t *testing.T) {
+	cfg := CORSConfig{AllowOrigins: []string{"*"}}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Origin", "https://anything.example.com")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_CredentialsMode_EchoesSpecificOrigin(t *testing.T) {
+	cfg := CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORSMiddleware_OriginFunc_TakesPrecedence(t *testing.T) {
+	cfg := CORSConfig{
+		AllowOrigins: []string{"https://app.example.com"},
+		AllowOriginFunc: func(origin string) bool {
+			return origin == "https://dynamic.example.com"
+		},
+	}
+	handler := CORSMiddleware(cfg)(passthroughHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set("Origin", "https://dynamic.example.com")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "https://dynamic.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestDefaultCORSConfig_ExposesCorrelationHeader(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	assert.Contains(t, cfg.ExposeHeaders, CorrelationIDHeader)
+}
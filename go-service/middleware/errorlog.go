@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// validationErrorLogCapacity bounds how many ValidationErrors
+// logValidationErrors retains - the same cap GetValidationErrors has always
+// enforced, now backed by a ring buffer instead of a truncated slice.
+const validationErrorLogCapacity = 100
+
+// validationErrorRing is a fixed-capacity, thread-safe ring buffer of
+// ValidationErrors. Once full, each new entry overwrites the oldest one, so
+// memory use never grows with traffic regardless of how many requests fail
+// validation.
+type validationErrorRing struct {
+	mu      sync.RWMutex
+	entries []ValidationError
+	next    int
+	full    bool
+}
+
+func newValidationErrorRing(capacity int) *validationErrorRing {
+	return &validationErrorRing{entries: make([]ValidationError, capacity)}
+}
+
+func (r *validationErrorRing) add(errs ...ValidationError) {
+	if len(errs) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range errs {
+		r.entries[r.next] = e
+		r.next++
+		if r.next == len(r.entries) {
+			r.next = 0
+			r.full = true
+		}
+	}
+}
+
+// snapshot returns every retained entry, oldest first.
+func (r *validationErrorRing) snapshot() []ValidationError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.full {
+		result := make([]ValidationError, r.next)
+		copy(result, r.entries[:r.next])
+		return result
+	}
+
+	result := make([]ValidationError, len(r.entries))
+	n := copy(result, r.entries[r.next:])
+	copy(result[n:], r.entries[:r.next])
+	return result
+}
+
+func (r *validationErrorRing) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = make([]ValidationError, len(r.entries))
+	r.next = 0
+	r.full = false
+}
+
+var validationLog = newValidationErrorRing(validationErrorLogCapacity)
+
+// GetValidationErrors returns the last validationErrorLogCapacity logged
+// ValidationErrors, oldest first.
+func GetValidationErrors() []ValidationError {
+	return validationLog.snapshot()
+}
+
+// ClearValidationErrors empties the validation error log.
+func ClearValidationErrors() {
+	validationLog.clear()
+}
+
+func logValidationErrors(errors []ValidationError) {
+	validationLog.add(errors...)
+}
+
+// FilterValidationErrors narrows errs to those matching field (exact match,
+// ignored if empty) and within [since, until] (either bound ignored if
+// zero). GET /admin/validation-errors (see api.Handler.ValidationErrors)
+// uses this to apply its field/time-range query parameters.
+func FilterValidationErrors(errs []ValidationError, field string, since, until time.Time) []ValidationError {
+	var result []ValidationError
+	for _, e := range errs {
+		if field != "" && e.Field != field {
+			continue
+		}
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && e.Time.After(until) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// ExportValidationErrors writes the current validation error log to w as
+// either newline-delimited JSON ("json", the default) or CSV ("csv"), for
+// shipping to a log aggregator that doesn't speak either format natively.
+func ExportValidationErrors(w io.Writer, format string) error {
+	errs := GetValidationErrors()
+
+	switch strings.ToLower(format) {
+	case "", "json":
+		return exportValidationErrorsJSON(w, errs)
+	case "csv":
+		return exportValidationErrorsCSV(w, errs)
+	default:
+		return fmt.Errorf("unsupported validation error export format %q", format)
+	}
+}
+
+func exportValidationErrorsJSON(w io.Writer, errs []ValidationError) error {
+	enc := json.NewEncoder(w)
+	for _, e := range errs {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportValidationErrorsCSV(w io.Writer, errs []ValidationError) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"field", "reason", "timestamp", "request_id"}); err != nil {
+		return err
+	}
+	for _, e := range errs {
+		row := []string{e.Field, e.Reason, e.Time.Format(time.RFC3339Nano), e.RequestID}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationErrorRing_OverwritesOldestOnceFull(t *testing.T) {
+	ring := newValidationErrorRing(3)
+	ring.add(
+		ValidationError{Field: "a"},
+		ValidationError{Field: "b"},
+		ValidationError{Field: "c"},
+		ValidationError{Field: "d"},
+	)
+
+	got := ring.snapshot()
+	assert.Len(t, got, 3)
+	assert.Equal(t, []string{"b", "c", "d"}, []string{got[0].Field, got[1].Field, got[2].Field})
+}
+
+func TestValidationErrorRing_ClearEmptiesLog(t *testing.T) {
+	ring := newValidationErrorRing(2)
+	ring.add(ValidationError{Field: "a"})
+	ring.clear()
+	assert.Empty(t, ring.snapshot())
+}
+
+func TestFilterValidationErrors_ByFieldAndTimeRange(t *testing.T) {
+	now := time.Now()
+	errs := []ValidationError{
+		{Field: "content", Time: now.Add(-2 * time.Hour)},
+		{Field: "path", Time: now.Add(-1 * time.Hour)},
+		{Field: "content", Time: now},
+	}
+
+	got := FilterValidationErrors(errs, "content", time.Time{}, time.Time{})
+	assert.Len(t, got, 2)
+
+	got = FilterValidationErrors(errs, "", now.Add(-90*time.Minute), time.Time{})
+	assert.Len(t, got, 2)
+
+	got = FilterValidationErrors(errs, "content", now.Add(-90*time.Minute), time.Time{})
+	assert.Len(t, got, 1)
+	assert.Equal(t, now.Unix(), got[0].Time.Unix())
+}
+
+func TestExportValidationErrors_JSONAndCSV(t *testing.T) {
+	ClearValidationErrors()
+	t.Cleanup(ClearValidationErrors)
+	_ = ValidateParseRequest("", "ok")
+
+	var jsonBuf bytes.Buffer
+	assert.NoError(t, ExportValidationErrors(&jsonBuf, "json"))
+	assert.Contains(t, jsonBuf.String(), `"field":"content"`)
+
+	var csvBuf bytes.Buffer
+	assert.NoError(t, ExportValidationErrors(&csvBuf, "csv"))
+	lines := strings.Split(strings.TrimSpace(csvBuf.String()), "\n")
+	assert.Equal(t, "field,reason,timestamp,request_id", lines[0])
+	assert.Contains(t, lines[1], "content,")
+}
+
+func TestExportValidationErrors_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, ExportValidationErrors(&buf, "xml"))
+}
+
+func TestValidationMiddleware_StampsRequestIDFromCorrelationHeader(t *testing.T) {
+	ClearValidationErrors()
+	t.Cleanup(ClearValidationErrors)
+
+	in := `{"content":"","path":"ok.go"}`
+	req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewBufferString(in))
+	req.Header.Set(CorrelationIDHeader, "test-correlation-id-123")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	ValidationMiddleware(next).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	logged := GetValidationErrors()
+	if assert.Len(t, logged, 1) {
+		assert.Equal(t, "test-correlation-id-123", logged[0].RequestID)
+	}
+}
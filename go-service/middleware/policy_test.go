@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules_IndividualChecks(t *testing.T) {
+	assert.Nil(t, RequiredRule{Field: "content"}.Check(map[string]string{"content": "x"}))
+	err := RequiredRule{Field: "content"}.Check(map[string]string{"content": ""})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Equal(t, "content", err.Field)
+	assert.Contains(t, err.Reason, "Content is required")
+
+	assert.Nil(t, MaxSizeRule{Field: "content", Limit: 5}.Check(map[string]string{"content": "abcde"}))
+	err = MaxSizeRule{Field: "content", Limit: 5}.Check(map[string]string{"content": "abcdef"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Contains(t, err.Reason, "exceeds maximum size")
+
+	assert.Nil(t, MaxPathLengthRule{Field: "path", Limit: 5}.Check(map[string]string{"path": "abcde"}))
+	err = MaxPathLengthRule{Field: "path", Limit: 5}.Check(map[string]string{"path": "abcdef"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Contains(t, err.Reason, "exceeds maximum length")
+
+	err = NoNullBytesRule{Field: "content"}.Check(map[string]string{"content": "a\x00b"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Contains(t, err.Reason, "null bytes")
+
+	err = NoPathTraversalRule{Field: "path"}.Check(map[string]string{"path": "../etc/passwd"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Contains(t, err.Reason, "directory traversal")
+	assert.Nil(t, NoPathTraversalRule{Field: "path"}.Check(map[string]string{"path": "src/main.go"}))
+}
+
+func TestRegexRule_MatchesAndCachesCompiledPattern(t *testing.T) {
+	rule := &RegexRule{Field: "content", Pattern: `(?i)drop\s+table`}
+
+	err := rule.Check(map[string]string{"content": "DROP TABLE users"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Contains(t, err.Reason, "disallowed pattern")
+	assert.NotNil(t, rule.compiled)
+
+	assert.Nil(t, rule.Check(map[string]string{"content": "select * from users"}))
+}
+
+func TestRegexRule_CustomReason(t *testing.T) {
+	rule := &RegexRule{Field: "content", Pattern: `secret`, Reason: "content looks like a leaked secret"}
+	err := rule.Check(map[string]string{"content": "my secret key"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Equal(t, "content looks like a leaked secret", err.Reason)
+}
+
+func TestFuncRule_WrapsArbitraryCheck(t *testing.T) {
+	rule := FuncRule{
+		Name: "even-length",
+		Fn: func(fields map[string]string) *ValidationError {
+			if len(fields["content"])%2 != 0 {
+				return newFieldError("content", "content must have even length")
+			}
+			return nil
+		},
+	}
+
+	assert.Nil(t, rule.Check(map[string]string{"content": "ab"}))
+	err := rule.Check(map[string]string{"content": "abc"})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	assert.Equal(t, "content must have even length", err.Reason)
+}
+
+func TestPolicySet_PolicyFor_MatchAndNoMatch(t *testing.T) {
+	ps := DefaultPolicySet()
+
+	p, ok := ps.PolicyFor(http.MethodPost, "/parse")
+	assert.True(t, ok)
+	assert.Len(t, p.Rules, 5)
+
+	p, ok = ps.PolicyFor("post", "/parse")
+	assert.True(t, ok)
+	assert.Len(t, p.Rules, 5)
+
+	_, ok = ps.PolicyFor(http.MethodPost, "/unknown")
+	assert.False(t, ok)
+}
+
+func TestPolicySet_Validate_NoMatchingPolicyPassesThrough(t *testing.T) {
+	ps := DefaultPolicySet()
+	errs := ps.Validate(http.MethodPost, "/", "", map[string]string{"content": ""})
+	assert.Empty(t, errs)
+}
+
+func TestLoadPolicySet_JSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	body := `[
+		{
+			"method": "POST",
+			"path": "/upload",
+			"rules": [
+				{"kind": "required", "field": "content"},
+				{"kind": "maxsize", "field": "content", "limit": 10},
+				{"kind": "regex", "field": "content", "pattern": "bad", "reason": "content contains a banned word"}
+			]
+		}
+	]`
+	assert.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+
+	ps, err := LoadPolicySet(path)
+	assert.NoError(t, err)
+
+	errs := ps.Validate(http.MethodPost, "/upload", "", map[string]string{"content": ""})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "content", errs[0].Field)
+
+	errs = ps.Validate(http.MethodPost, "/upload", "", map[string]string{"content": "is bad"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "content contains a banned word", errs[0].Reason)
+
+	errs = ps.Validate(http.MethodPost, "/upload", "", map[string]string{"content": "fine"})
+	assert.Empty(t, errs)
+}
+
+func TestLoadPolicySet_YAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	body := `
+- method: POST
+  path: /upload
+  rules:
+    - kind: required
+      field: content
+    - kind: maxpathlength
+      field: path
+      limit: 4
+`
+	assert.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+
+	ps, err := LoadPolicySet(path)
+	assert.NoError(t, err)
+
+	errs := ps.Validate(http.MethodPost, "/upload", "", map[string]string{"content": "ok", "path": "abcde"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "path", errs[0].Field)
+}
+
+func TestLoadPolicySet_UnknownRuleKind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	body := `[{"method": "POST", "path": "/upload", "rules": [{"kind": "nope", "field": "content"}]}]`
+	assert.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+
+	_, err := LoadPolicySet(path)
+	assert.Error(t, err)
+}
+
+func TestLoadPolicySet_MissingFile(t *testing.T) {
+	_, err := LoadPolicySet(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestSetPolicySet_OverridesActivePolicy(t *testing.T) {
+	t.Cleanup(func() { SetPolicySet(DefaultPolicySet()) })
+
+	custom := &PolicySet{policies: []Policy{
+		{Method: http.MethodPost, Path: "/custom", Rules: []Rule{RequiredRule{Field: "content"}}},
+	}}
+	SetPolicySet(custom)
+
+	errs := ValidateParseRequest("", "ok")
+	assert.Empty(t, errs, "the custom policy has no /parse entry, so /parse requests pass unchanged")
+
+	got := currentPolicySet().Validate(http.MethodPost, "/custom", "", map[string]string{"content": ""})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "content", got[0].Field)
+}
+
+func TestValidationMiddleware_RejectsRequestFailingPolicy(t *testing.T) {
+	in := `{"content":"","path":"ok.go"}`
+	req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewBufferString(in))
+
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	ValidationMiddleware(next).ServeHTTP(rr, req)
+
+	assert.False(t, called, "next must not run when the policy rejects the request")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var body map[string][]ValidationError
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Len(t, body["errors"], 1)
+	assert.Equal(t, "content", body["errors"][0].Field)
+}
+
+func TestValidationMiddleware_PassesRequestSatisfyingPolicy(t *testing.T) {
+	in := `{"content":"package main","path":"ok.go"}`
+	req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewBufferString(in))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	})
+
+	rr := httptest.NewRecorder()
+	ValidationMiddleware(next).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var got map[string]string
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, "package main", got["content"])
+	assert.Equal(t, "ok.go", got["path"])
+}
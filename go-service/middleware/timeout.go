@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// RequestTimeoutHeader lets a caller request a shorter (or, once
+	// clamped, longer) per-request deadline than the server default.
+	RequestTimeoutHeader = "X-Request-Timeout"
+
+	// DefaultMaxTimeout is the ceiling applied to RequestTimeoutHeader when
+	// TimeoutMiddleware is built without an explicit max via
+	// TimeoutMiddlewareWithMax.
+	DefaultMaxTimeout = 2 * time.Minute
+)
+
+type requestDeadlineKey struct{}
+
+// requestDeadline is the per-request analogue of gonet's deadlineTimer
+// (see gvisor's tcpip/transport/tcp deadlineTimer): a timer paired with a
+// cancel func so the deadline can be pushed out mid-request instead of
+// being fixed for the lifetime of the context.
+type requestDeadline struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	timer  *time.Timer
+}
+
+func newRequestDeadline(parent context.Context, d time.Duration) (context.Context, *requestDeadline) {
+	ctx, cancel := context.WithCancel(parent)
+	rd := &requestDeadline{cancel: cancel}
+	rd.timer = time.AfterFunc(d, cancel)
+	return context.WithValue(ctx, requestDeadlineKey{}, rd), rd
+}
+
+func (rd *requestDeadline) reset(t time.Time) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	rd.timer.Stop()
+
+	d := time.Until(t)
+	if d <= 0 {
+		rd.cancel()
+		return
+	}
+	rd.timer.Reset(d)
+}
+
+func (rd *requestDeadline) stop() {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.timer.Stop()
+}
+
+// ResetDeadline pushes a request's deadline (set up by TimeoutMiddleware)
+// out to t, so a handler doing long-running work in stages can avoid being
+// cut off early. It reports whether ctx carried a deadline to reset.
+func ResetDeadline(ctx context.Context, t time.Time) bool {
+	rd, ok := ctx.Value(requestDeadlineKey{}).(*requestDeadline)
+	if !ok {
+		return false
+	}
+	rd.reset(t)
+	return true
+}
+
+// TimeoutMiddleware attaches a context.Context with deadline d to each
+// request, cancelling it (and any parser operations threading ctx through)
+// if it runs past the deadline. A client may request a shorter or longer
+// deadline via the X-Request-Timeout header (e.g. "250ms"), clamped to
+// DefaultMaxTimeout.
+func TimeoutMiddleware(d time.Duration) func(http.Handler) http.Handler {
+	return TimeoutMiddlewareWithMax(d, DefaultMaxTimeout)
+}
+
+// TimeoutMiddlewareWithMax is TimeoutMiddleware with an explicit ceiling for
+// the X-Request-Timeout header instead of DefaultMaxTimeout. Even after
+// writing the 504, it waits for next's goroutine to return before ServeHTTP
+// itself returns, so callers never observe next still running against sw/req
+// in the background; that wait only completes once next notices ctx is done
+// and returns, so a handler that ignores ctx will still hang here.
+func TimeoutMiddlewareWithMax(d, max time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := requestedTimeout(r.Header.Get(RequestTimeoutHeader), d, max)
+
+			ctx, rd := newRequestDeadline(r.Context(), timeout)
+			defer rd.stop()
+
+			sw := &singleWriteResponseWriter{ResponseWriter: w}
+			req := r.WithContext(ctx)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				next.ServeHTTP(sw, req)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				writeTimeoutResponse(sw, req)
+				<-done
+			}
+		})
+	}
+}
+
+func requestedTimeout(header string, def, max time.Duration) time.Duration {
+	timeout := def
+	if header != "" {
+		if parsed, err := time.ParseDuration(header); err == nil && parsed > 0 {
+			timeout = parsed
+		}
+	}
+	if max > 0 && timeout > max {
+		timeout = max
+	}
+	return timeout
+}
+
+// writeTimeoutResponse claims the response for the timeout path and, if it
+// won the race against the handler's own first write, sends 504. If the
+// handler had already started writing, the status line is already
+// committed and this is a no-op: ctx is still cancelled, so a handler that
+// checks it can still abort, but the wire format is left alone.
+func writeTimeoutResponse(w *singleWriteResponseWriter, r *http.Request) {
+	if !w.claimForTimeout() {
+		return
+	}
+
+	correlationID, _ := r.Context().Value(CorrelationIDKey).(string)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(CorrelationIDHeader, correlationID)
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(map[string]string{
+		"error":          "request timed out",
+		"correlation_id": correlationID,
+	})
+}
+
+// singleWriteResponseWriter arbitrates between TimeoutMiddleware's timeout
+// response and the handler goroutine it may still be racing: whichever one
+// writes (or is claimed for) first wins the underlying http.ResponseWriter,
+// and every write attempt from the other side after that is silently
+// dropped instead of corrupting the response.
+type singleWriteResponseWriter struct {
+	http.ResponseWriter
+
+	mu        sync.Mutex
+	started   bool
+	timedOut  bool
+	wroteBody bool
+}
+
+// claimForTimeout reserves the response for the timeout writer. It reports
+// false if the handler already started writing, meaning the timeout
+// response must not be sent.
+func (w *singleWriteResponseWriter) claimForTimeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return false
+	}
+	w.started = true
+	w.timedOut = true
+	return true
+}
+
+func (w *singleWriteResponseWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write claims the body write the same way claimForTimeout claims the
+// status line: only the first Write call (across every goroutine racing on
+// w) reaches the underlying ResponseWriter. Every later call - whether it
+// lost that race or arrives after a timeout already claimed the response -
+// reports success to its caller but silently drops the bytes.
+func (w *singleWriteResponseWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	if w.timedOut || w.wroteBody {
+		w.mu.Unlock()
+		return len(b), nil
+	}
+	w.wroteBody = true
+	w.started = true
+	w.mu.Unlock()
+
+	return w.ResponseWriter.Write(b)
+}
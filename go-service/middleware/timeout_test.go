@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutMiddleware_FastHandler_PassesThrough(t *testing.T) {
+	handler := TimeoutMiddleware(50 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "ok", rr.Body.String())
+}
+
+func TestTimeoutMiddleware_SlowHandler_Returns504(t *testing.T) {
+	blocked := make(chan struct{})
+	handler := TimeoutMiddleware(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(blocked)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set(CorrelationIDHeader, "deadbeef-1234567890")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "request timed out", body["error"])
+
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("handler never observed context cancellation")
+	}
+}
+
+func TestTimeoutMiddleware_HeaderRequestsShorterTimeout(t *testing.T) {
+	var sawDeadline bool
+	handler := TimeoutMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			sawDeadline = true
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set(RequestTimeoutHeader, "10ms")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	assert.True(t, sawDeadline)
+}
+
+func TestTimeoutMiddleware_HeaderClampedToMax(t *testing.T) {
+	handler := TimeoutMiddlewareWithMax(time.Minute, 20*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	req.Header.Set(RequestTimeoutHeader, "1h") // well over the 20ms max
+	rr := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(rr, req)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestResetDeadline_ExtendsTimeout(t *testing.T) {
+	handler := TimeoutMiddleware(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ResetDeadline(r.Context(), time.Now().Add(200*time.Millisecond))
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestResetDeadline_NoDeadlineOnContext_ReturnsFalse(t *testing.T) {
+	assert.False(t, ResetDeadline(context.Background(), time.Now().Add(time.Minute)))
+}
+
+func TestSingleWriteResponseWriter_ConcurrentWrites_OnlyFirstWins(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sw := &singleWriteResponseWriter{ResponseWriter: rr}
+
+	var wg sync.WaitGroup
+	var successes int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sw.WriteHeader(200 + i)
+			n, err := sw.Write([]byte("x"))
+			if err == nil && n == 1 {
+				atomic.AddInt32(&successes, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(50), atomic.LoadInt32(&successes)) // Write always "succeeds" from the caller's view
+	assert.Len(t, rr.Body.String(), 1)                       // but only one byte ever reached the real writer
+}
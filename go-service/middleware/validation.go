@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 	"unicode"
 )
@@ -17,92 +16,32 @@ const (
 )
 
 type ValidationError struct {
-	Field  string    `json:"field"`
-	Reason string    `json:"reason"`
-	Time   time.Time `json:"timestamp"`
+	Field     string    `json:"field"`
+	Reason    string    `json:"reason"`
+	Time      time.Time `json:"timestamp"`
+	RequestID string    `json:"request_id,omitempty"`
 }
 
-var (
-	validationErrors []ValidationError
-	validationMutex  sync.RWMutex
-)
-
+// ValidateParseRequest runs the active PolicySet's rules for "POST /parse"
+// against content and path (see DefaultPolicySet, SetPolicySet). Every
+// failure is logged via logValidationErrors and returned, not just the
+// first one encountered. There's no request to derive a RequestID from
+// here, so every returned error's RequestID is empty; ValidationMiddleware
+// is what populates it for HTTP-originated validation.
 func ValidateParseRequest(content, path string) []ValidationError {
-	var errors []ValidationError
-
-	if content == "" {
-		errors = append(errors, ValidationError{
-			Field:  "content",
-			Reason: "Content is required and cannot be empty",
-			Time:   time.Now(),
-		})
-	} else if len(content) > MaxContentSize {
-		errors = append(errors, ValidationError{
-			Field:  "content",
-			Reason: "Content exceeds maximum size of 1MB",
-			Time:   time.Now(),
-		})
-	} else if containsNullBytes(content) {
-		errors = append(errors, ValidationError{
-			Field:  "content",
-			Reason: "Content contains invalid null bytes",
-			Time:   time.Now(),
-		})
-	}
-
-	if len(path) > MaxPathLength {
-		errors = append(errors, ValidationError{
-			Field:  "path",
-			Reason: "Path exceeds maximum length",
-			Time:   time.Now(),
-		})
-	}
-
-	if strings.Contains(path, "..") || strings.Contains(path, "~/") {
-		errors = append(errors, ValidationError{
-			Field:  "path",
-			Reason: "Path contains potential directory traversal",
-			Time:   time.Now(),
-		})
-	}
-
-	logValidationErrors(errors)
-	return errors
+	return currentPolicySet().Validate(http.MethodPost, "/parse", "", map[string]string{
+		"content": content,
+		"path":    path,
+	})
 }
 
+// ValidateDiffRequest runs the active PolicySet's rules for "POST /diff"
+// against oldContent and newContent (see DefaultPolicySet, SetPolicySet).
 func ValidateDiffRequest(oldContent, newContent string) []ValidationError {
-	var errors []ValidationError
-
-	if oldContent == "" {
-		errors = append(errors, ValidationError{
-			Field:  "old_content",
-			Reason: "Old content is required",
-			Time:   time.Now(),
-		})
-	} else if len(oldContent) > MaxContentSize {
-		errors = append(errors, ValidationError{
-			Field:  "old_content",
-			Reason: "Old content exceeds maximum size",
-			Time:   time.Now(),
-		})
-	}
-
-	if newContent == "" {
-		errors = append(errors, ValidationError{
-			Field:  "new_content",
-			Reason: "New content is required",
-			Time:   time.Now(),
-		})
-	} else if len(newContent) > MaxContentSize {
-		errors = append(errors, ValidationError{
-			Field:  "new_content",
-			Reason: "New content exceeds maximum size",
-			Time:   time.Now(),
-		})
-	}
-
-	logValidationErrors(errors)
-	return errors
+	return currentPolicySet().Validate(http.MethodPost, "/diff", "", map[string]string{
+		"old_content": oldContent,
+		"new_content": newContent,
+	})
 }
 
 func SanitizeInput(input string) string {
@@ -149,6 +88,17 @@ func SanitizeRequestBody(r *http.Request) {
 	}
 }
 
+// validatedFields are the request body keys Policy rules and sanitization
+// both look at. Only these are ever rewritten by ValidationMiddleware.
+var validatedFields = []string{"content", "path", "old_content", "new_content"}
+
+// ValidationMiddleware sanitizes a POST request's content/path/old_content/
+// new_content fields and, if the active PolicySet (see SetPolicySet) has a
+// Policy for the request's method and path, first runs that Policy's Rules
+// against them. A request that fails any Rule gets a structured 400
+// listing every failure instead of being silently sanitized and passed
+// through; a request with no matching Policy is only sanitized, same as
+// before Policy-based validation existed.
 func ValidationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -166,17 +116,21 @@ func ValidationMiddleware(next http.Handler) http.Handler {
 
 		var data map[string]interface{}
 		if err := json.Unmarshal(bodyBytes, &data); err == nil {
-			if content, ok := data["content"].(string); ok {
-				data["content"] = SanitizeInput(content)
+			fields := make(map[string]string, len(validatedFields))
+			for _, key := range validatedFields {
+				if v, ok := data[key].(string); ok {
+					fields[key] = v
+				}
 			}
-			if path, ok := data["path"].(string); ok {
-				data["path"] = SanitizeInput(path)
-			}
-			if oldContent, ok := data["old_content"].(string); ok {
-				data["old_content"] = SanitizeInput(oldContent)
+
+			requestID := extractOrGenerateCorrelationID(r)
+			if errs := currentPolicySet().Validate(r.Method, r.URL.Path, requestID, fields); len(errs) > 0 {
+				writeValidationErrorResponse(w, errs)
+				return
 			}
-			if newContent, ok := data["new_content"].(string); ok {
-				data["new_content"] = SanitizeInput(newContent)
+
+			for key, v := range fields {
+				data[key] = SanitizeInput(v)
 			}
 
 			sanitizedBody, _ := json.Marshal(data)
@@ -188,36 +142,15 @@ func ValidationMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func GetValidationErrors() []ValidationError {
-	validationMutex.RLock()
-	defer validationMutex.RUnlock()
-
-	result := make([]ValidationError, len(validationErrors))
-	copy(result, validationErrors)
-	return result
-}
-
-func ClearValidationErrors() {
-	validationMutex.Lock()
-	defer validationMutex.Unlock()
-	validationErrors = []ValidationError{}
+// writeValidationErrorResponse writes a structured 400 response listing
+// every rule failure, so a caller sees every problem with their request at
+// once instead of fixing one error per round trip.
+func writeValidationErrorResponse(w http.ResponseWriter, errs []ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
 }
 
 func containsNullBytes(s string) bool {
 	return strings.Contains(s, "\x00")
 }
-
-func logValidationErrors(errors []ValidationError) {
-	if len(errors) == 0 {
-		return
-	}
-
-	validationMutex.Lock()
-	defer validationMutex.Unlock()
-
-	validationErrors = append(validationErrors, errors...)
-
-	if len(validationErrors) > 100 {
-		validationErrors = validationErrors[len(validationErrors)-100:]
-	}
-}
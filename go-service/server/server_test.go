@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ServesUntilContextCancelled(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	srv := New(handler, Config{Addr: lis.Addr().String(), ShutdownTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, lis) }()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + lis.Addr().String())
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after context cancellation")
+	}
+
+	_, err = http.Get("http://" + lis.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestServer_Addr(t *testing.T) {
+	srv := New(http.NotFoundHandler(), Config{Addr: ":9090"})
+	assert.Equal(t, ":9090", srv.Addr())
+}
@@ -0,0 +1,170 @@
+// Package tracing extracts the correlation/trace-context logic behind
+// middleware.CorrelationIDMiddleware into a framework-agnostic Core, so it
+// can be wired into net/http, Gin, Echo, or Fiber through a thin adapter
+// instead of being reimplemented (or only available) for one of them. Every
+// adapter records the same middleware.TraceData to the same trace storage
+// and, if a middleware.Tracer is attached, the same sampled export
+// pipeline - they differ only in how they read their framework's request
+// and capture its response status/body.
+package tracing
+
+import (
+	"time"
+
+	"polyglot-codebase/go-service/middleware"
+)
+
+const defaultMaxBodyBytes = 4096
+
+// Core holds the tracing/correlation logic shared by every adapter in this
+// package.
+type Core struct {
+	// CaptureBody, when true, attaches the request/response bodies to the
+	// recorded TraceData's Attributes ("request.body"/"response.body"),
+	// each truncated to MaxBodyBytes. Off by default - most deployments
+	// don't want payloads sitting in trace storage.
+	CaptureBody  bool
+	MaxBodyBytes int
+
+	tracer *middleware.Tracer
+}
+
+// Option configures a Core built by NewCore.
+type Option func(*Core)
+
+// WithBodyCapture enables request/response body capture on recorded spans.
+// maxBytes caps how much of each body is kept; 0 keeps the default of 4096
+// bytes.
+func WithBodyCapture(maxBytes int) Option {
+	return func(c *Core) {
+		c.CaptureBody = true
+		if maxBytes > 0 {
+			c.MaxBodyBytes = maxBytes
+		}
+	}
+}
+
+// WithTracer attaches a *middleware.Tracer so a sampled subset of spans is
+// additionally shipped to an external collector, not just recorded to
+// trace storage.
+func WithTracer(t *middleware.Tracer) Option {
+	return func(c *Core) { c.tracer = t }
+}
+
+// NewCore builds a Core with opts applied.
+func NewCore(opts ...Option) *Core {
+	c := &Core{MaxBodyBytes: defaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RequestInfo is the minimal view of an inbound request Core needs to
+// start a Span. Each adapter fills it in from its framework's native
+// request type.
+type RequestInfo struct {
+	Method string
+	Path   string
+	Header func(key string) string
+	Body   []byte
+}
+
+// Span is one request's in-flight trace bookkeeping, returned by
+// Core.Start and completed by Span.Finish once the adapter knows the
+// final response status (and, with CaptureBody, the response body).
+type Span struct {
+	core   *Core
+	tc     middleware.TraceContext
+	corrID string
+	data   middleware.TraceData
+}
+
+// CorrelationID is the resolved correlation id for this request; adapters
+// propagate it into their framework's context under this value.
+func (s *Span) CorrelationID() string { return s.corrID }
+
+// TraceContext is the resolved W3C trace/span identifiers for this
+// request; adapters propagate it into their framework's context the same
+// way.
+func (s *Span) TraceContext() middleware.TraceContext { return s.tc }
+
+// TraceParent is the traceparent header value adapters set on the
+// response: a valid incoming traceparent unchanged, else a freshly minted one.
+func (s *Span) TraceParent() string {
+	if s.tc.ParentSpanID != "" {
+		return middleware.BuildTraceParent(s.tc.TraceID, s.tc.ParentSpanID, s.tc.Flags)
+	}
+	return middleware.BuildTraceParent(s.tc.TraceID, s.tc.SpanID, s.tc.Flags)
+}
+
+// TraceState is the tracestate header value adapters set on the response,
+// if any.
+func (s *Span) TraceState() string { return s.tc.TraceState }
+
+// SetAttribute attaches a key/value pair to the span's recorded TraceData,
+// creating the underlying Attributes map on first use. Handlers use this to
+// tag a span with domain-specific context (e.g. the language or line count
+// a parse request was for) that Core itself has no way to know. Safe to
+// call multiple times before Finish; a later call for the same key
+// overwrites it.
+func (s *Span) SetAttribute(key, value string) {
+	if s.data.Attributes == nil {
+		s.data.Attributes = map[string]string{}
+	}
+	s.data.Attributes[key] = value
+}
+
+// Start resolves info's trace context and begins timing the request.
+func (c *Core) Start(info RequestInfo) *Span {
+	tc, corrID := middleware.ResolveTraceContextFromHeader(info.Header)
+
+	startTime := time.Now()
+	data := middleware.TraceData{
+		Service:       "go-parser",
+		Method:        info.Method,
+		Path:          info.Path,
+		Timestamp:     startTime,
+		CorrelationID: corrID,
+		TraceID:       tc.TraceID,
+		ParentSpanID:  tc.ParentSpanID,
+		SpanID:        tc.SpanID,
+		StartTime:     startTime,
+	}
+
+	if c.CaptureBody && len(info.Body) > 0 {
+		data.Attributes = map[string]string{"request.body": truncate(info.Body, c.MaxBodyBytes)}
+	}
+
+	return &Span{core: c, tc: tc, corrID: corrID, data: data}
+}
+
+// Finish completes span with the final response status (and, with
+// CaptureBody, the response body), records it to trace storage, and hands
+// it to the attached Tracer for sampled export, if any.
+func (s *Span) Finish(status int, respBody []byte) {
+	endTime := time.Now()
+	s.data.EndTime = endTime
+	s.data.DurationMS = float64(endTime.Sub(s.data.StartTime).Milliseconds())
+	s.data.Status = status
+
+	if s.core.CaptureBody && len(respBody) > 0 {
+		if s.data.Attributes == nil {
+			s.data.Attributes = map[string]string{}
+		}
+		s.data.Attributes["response.body"] = truncate(respBody, s.core.MaxBodyBytes)
+	}
+
+	middleware.StoreTrace(s.corrID, s.data)
+
+	if s.core.tracer != nil {
+		s.core.tracer.RecordSampled(s.tc, s.data)
+	}
+}
+
+func truncate(b []byte, max int) string {
+	if max <= 0 || len(b) <= max {
+		return string(b)
+	}
+	return string(b[:max])
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"polyglot-codebase/go-service/middleware"
+)
+
+// Echo returns an echo.MiddlewareFunc built on Core. Status is read from
+// echo.Response.Status, which Echo's own ResponseWriter wrapper maintains,
+// rather than a net/http shim.
+func (c *Core) Echo() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ec echo.Context) error {
+			req := ec.Request()
+
+			var reqBody []byte
+			if c.CaptureBody {
+				reqBody, req.Body = readAndRestore(req.Body)
+			}
+
+			span := c.Start(RequestInfo{
+				Method: req.Method,
+				Path:   req.URL.Path,
+				Header: req.Header.Get,
+				Body:   reqBody,
+			})
+
+			reqCtx := context.WithValue(req.Context(), middleware.CorrelationIDKey, span.CorrelationID())
+			reqCtx = context.WithValue(reqCtx, middleware.TraceContextKey, span.TraceContext())
+			ec.SetRequest(req.WithContext(reqCtx))
+			ec.Set(middleware.CorrelationIDKey, span.CorrelationID())
+
+			ec.Response().Header().Set(middleware.CorrelationIDHeader, span.CorrelationID())
+			ec.Response().Header().Set(middleware.TraceParentHeader, span.TraceParent())
+			if ts := span.TraceState(); ts != "" {
+				ec.Response().Header().Set(middleware.TraceStateHeader, ts)
+			}
+
+			var bodyBuf *bytes.Buffer
+			if c.CaptureBody {
+				bodyBuf = &bytes.Buffer{}
+				ec.Response().Writer = &teeResponseWriter{ResponseWriter: ec.Response().Writer, tee: bodyBuf}
+			}
+
+			err := next(ec)
+
+			var respBody []byte
+			if bodyBuf != nil {
+				respBody = bodyBuf.Bytes()
+			}
+			span.Finish(ec.Response().Status, respBody)
+
+			return err
+		}
+	}
+}
+
+// teeResponseWriter tees everything written through Echo's
+// http.ResponseWriter into a buffer so the adapter can attach the response
+// body to the finished span.
+type teeResponseWriter struct {
+	http.ResponseWriter
+	tee *bytes.Buffer
+}
+
+func (w *teeResponseWriter) Write(b []byte) (int, error) {
+	w.tee.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"polyglot-codebase/go-service/middleware"
+)
+
+func TestCore_Echo_PropagatesCorrelationIDAndRecordsSpan(t *testing.T) {
+	core := NewCore()
+	e := echo.New()
+	e.Use(core.Echo())
+
+	var sawCorrelationID string
+	e.POST("/echo", func(c echo.Context) error {
+		sawCorrelationID, _ = c.Get(middleware.CorrelationIDKey).(string)
+		return c.NoContent(http.StatusCreated)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	e.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotEmpty(t, sawCorrelationID)
+	assert.Equal(t, sawCorrelationID, rr.Header().Get(middleware.CorrelationIDHeader))
+
+	traces := middleware.GetTraces(sawCorrelationID)
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, http.StatusCreated, traces[0].Status)
+	}
+}
+
+// TestCore_Echo_BodyCapture shows request/response body capture toggled
+// via WithBodyCapture, read back from the span recorded for the request.
+func TestCore_Echo_BodyCapture(t *testing.T) {
+	handler := func(c echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		assert.Equal(t, "ping", string(body))
+		return c.String(http.StatusOK, "pong")
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		core := NewCore()
+		e := echo.New()
+		e.Use(core.Echo())
+		e.POST("/echo", handler)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		e.ServeHTTP(rr, req)
+
+		traces := middleware.GetTraces(rr.Header().Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Empty(t, traces[0].Attributes["request.body"])
+			assert.Empty(t, traces[0].Attributes["response.body"])
+		}
+	})
+
+	t.Run("enabled via WithBodyCapture", func(t *testing.T) {
+		core := NewCore(WithBodyCapture(0))
+		e := echo.New()
+		e.Use(core.Echo())
+		e.POST("/echo", handler)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		e.ServeHTTP(rr, req)
+
+		traces := middleware.GetTraces(rr.Header().Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Equal(t, "ping", traces[0].Attributes["request.body"])
+			assert.Equal(t, "pong", traces[0].Attributes["response.body"])
+		}
+	})
+}
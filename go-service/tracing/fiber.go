@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"polyglot-codebase/go-service/middleware"
+)
+
+// Fiber returns a fiber.Handler built on Core. Fiber runs on fasthttp, not
+// net/http, so there's no http.Request/ResponseWriter to reuse here:
+// status and response body come from fiber.Ctx's own
+// *fasthttp.Response, and the correlation id is propagated via
+// ctx.Locals rather than context.Context.
+func (c *Core) Fiber() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var reqBody []byte
+		if c.CaptureBody {
+			reqBody = append([]byte(nil), ctx.Body()...)
+		}
+
+		span := c.Start(RequestInfo{
+			Method: ctx.Method(),
+			Path:   ctx.Path(),
+			Header: func(key string) string { return ctx.Get(key) },
+			Body:   reqBody,
+		})
+
+		ctx.Locals(middleware.CorrelationIDKey, span.CorrelationID())
+		ctx.Locals(middleware.TraceContextKey, span.TraceContext())
+
+		ctx.Set(middleware.CorrelationIDHeader, span.CorrelationID())
+		ctx.Set(middleware.TraceParentHeader, span.TraceParent())
+		if ts := span.TraceState(); ts != "" {
+			ctx.Set(middleware.TraceStateHeader, ts)
+		}
+
+		err := ctx.Next()
+
+		var respBody []byte
+		if c.CaptureBody {
+			respBody = append([]byte(nil), ctx.Response().Body()...)
+		}
+		span.Finish(ctx.Response().StatusCode(), respBody)
+
+		return err
+	}
+}
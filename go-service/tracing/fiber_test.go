@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"polyglot-codebase/go-service/middleware"
+)
+
+func TestCore_Fiber_PropagatesCorrelationIDAndRecordsSpan(t *testing.T) {
+	core := NewCore()
+	app := fiber.New()
+	app.Use(core.Fiber())
+
+	var sawCorrelationID string
+	app.Post("/echo", func(c *fiber.Ctx) error {
+		sawCorrelationID, _ = c.Locals(middleware.CorrelationIDKey).(string)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NotEmpty(t, sawCorrelationID)
+	assert.Equal(t, sawCorrelationID, resp.Header.Get(middleware.CorrelationIDHeader))
+
+	traces := middleware.GetTraces(sawCorrelationID)
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, http.StatusCreated, traces[0].Status)
+	}
+}
+
+// TestCore_Fiber_BodyCapture shows request/response body capture toggled
+// via WithBodyCapture, read back from the span recorded for the request.
+func TestCore_Fiber_BodyCapture(t *testing.T) {
+	handler := func(c *fiber.Ctx) error {
+		assert.Equal(t, "ping", string(c.Body()))
+		return c.SendString("pong")
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		core := NewCore()
+		app := fiber.New()
+		app.Use(core.Fiber())
+		app.Post("/echo", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		traces := middleware.GetTraces(resp.Header.Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Empty(t, traces[0].Attributes["request.body"])
+			assert.Empty(t, traces[0].Attributes["response.body"])
+		}
+	})
+
+	t.Run("enabled via WithBodyCapture", func(t *testing.T) {
+		core := NewCore(WithBodyCapture(0))
+		app := fiber.New()
+		app.Use(core.Fiber())
+		app.Post("/echo", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		traces := middleware.GetTraces(resp.Header.Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Equal(t, "ping", traces[0].Attributes["request.body"])
+			assert.Equal(t, "pong", traces[0].Attributes["response.body"])
+		}
+	})
+}
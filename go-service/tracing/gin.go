@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"polyglot-codebase/go-service/middleware"
+)
+
+// SpanContextKey is the Gin context key Core.Gin stores the in-flight
+// *Span under, so handlers downstream of the middleware can attach
+// additional attributes via SpanFromGinContext before the span is
+// finished.
+const SpanContextKey = "tracingSpan"
+
+// SpanFromGinContext returns the in-flight *Span that Core.Gin attached to
+// ctx, if any. Handlers that want to tag the current request's span with
+// domain-specific attributes (see Span.SetAttribute) call this first; it
+// reports false if no Core middleware ran for this request.
+func SpanFromGinContext(ctx *gin.Context) (*Span, bool) {
+	v, ok := ctx.Get(SpanContextKey)
+	if !ok {
+		return nil, false
+	}
+	span, ok := v.(*Span)
+	return span, ok
+}
+
+// Gin returns a gin.HandlerFunc built on Core. Status is read from Gin's
+// own gin.ResponseWriter.Status(), which Gin maintains on every handler's
+// ResponseWriter already, rather than a net/http shim.
+func (c *Core) Gin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var reqBody []byte
+		if c.CaptureBody {
+			reqBody, ctx.Request.Body = readAndRestore(ctx.Request.Body)
+		}
+
+		span := c.Start(RequestInfo{
+			Method: ctx.Request.Method,
+			Path:   ctx.Request.URL.Path,
+			Header: ctx.GetHeader,
+			Body:   reqBody,
+		})
+
+		reqCtx := context.WithValue(ctx.Request.Context(), middleware.CorrelationIDKey, span.CorrelationID())
+		reqCtx = context.WithValue(reqCtx, middleware.TraceContextKey, span.TraceContext())
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Set(middleware.CorrelationIDKey, span.CorrelationID())
+		ctx.Set(SpanContextKey, span)
+
+		ctx.Header(middleware.CorrelationIDHeader, span.CorrelationID())
+		ctx.Header(middleware.TraceParentHeader, span.TraceParent())
+		if ts := span.TraceState(); ts != "" {
+			ctx.Header(middleware.TraceStateHeader, ts)
+		}
+
+		var bodyBuf *bytes.Buffer
+		if c.CaptureBody {
+			bodyBuf = &bytes.Buffer{}
+			ctx.Writer = &teeGinWriter{ResponseWriter: ctx.Writer, tee: bodyBuf}
+		}
+
+		ctx.Next()
+
+		var respBody []byte
+		if bodyBuf != nil {
+			respBody = bodyBuf.Bytes()
+		}
+		span.Finish(ctx.Writer.Status(), respBody)
+	}
+}
+
+// teeGinWriter tees everything written through Gin's ResponseWriter into a
+// buffer so the adapter can attach the response body to the finished span,
+// without giving up any of Gin's own status/size bookkeeping.
+type teeGinWriter struct {
+	gin.ResponseWriter
+	tee *bytes.Buffer
+}
+
+func (w *teeGinWriter) Write(b []byte) (int, error) {
+	w.tee.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeGinWriter) WriteString(s string) (int, error) {
+	w.tee.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"polyglot-codebase/go-service/middleware"
+)
+
+func ginRouter(core *Core, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(core.Gin())
+	r.POST("/echo", handler)
+	return r
+}
+
+func TestCore_Gin_PropagatesCorrelationIDAndRecordsSpan(t *testing.T) {
+	core := NewCore()
+
+	var sawCorrelationID string
+	r := ginRouter(core, func(c *gin.Context) {
+		sawCorrelationID = c.GetString(middleware.CorrelationIDKey)
+		c.Status(http.StatusCreated)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotEmpty(t, sawCorrelationID)
+	assert.Equal(t, sawCorrelationID, rr.Header().Get(middleware.CorrelationIDHeader))
+
+	traces := middleware.GetTraces(sawCorrelationID)
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, http.StatusCreated, traces[0].Status)
+	}
+}
+
+// TestCore_Gin_SpanFromGinContext_AttributesSurviveToRecordedTraceData shows
+// a downstream handler attaching attributes to the span Core.Gin started
+// for the request, read back from the span once Core.Gin finishes it.
+func TestCore_Gin_SpanFromGinContext_AttributesSurviveToRecordedTraceData(t *testing.T) {
+	core := NewCore()
+	r := ginRouter(core, func(c *gin.Context) {
+		span, ok := SpanFromGinContext(c)
+		assert.True(t, ok)
+		span.SetAttribute("parser.language", "go")
+		c.Status(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	r.ServeHTTP(rr, req)
+
+	traces := middleware.GetTraces(rr.Header().Get(middleware.CorrelationIDHeader))
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, "go", traces[0].Attributes["parser.language"])
+	}
+}
+
+// TestCore_Gin_BodyCapture shows request/response body capture toggled via
+// WithBodyCapture, read back from the span recorded for the request.
+func TestCore_Gin_BodyCapture(t *testing.T) {
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		assert.Equal(t, "ping", string(body))
+		c.String(http.StatusOK, "pong")
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		core := NewCore()
+		r := ginRouter(core, handler)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewBufferString("ping"))
+		r.ServeHTTP(rr, req)
+
+		traces := middleware.GetTraces(rr.Header().Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Empty(t, traces[0].Attributes["request.body"])
+			assert.Empty(t, traces[0].Attributes["response.body"])
+		}
+	})
+
+	t.Run("enabled via WithBodyCapture", func(t *testing.T) {
+		core := NewCore(WithBodyCapture(0))
+		r := ginRouter(core, handler)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewBufferString("ping"))
+		r.ServeHTTP(rr, req)
+
+		traces := middleware.GetTraces(rr.Header().Get(middleware.CorrelationIDHeader))
+		if assert.Len(t, traces, 1) {
+			assert.Equal(t, "ping", traces[0].Attributes["request.body"])
+			assert.Equal(t, "pong", traces[0].Attributes["response.body"])
+		}
+	})
+}
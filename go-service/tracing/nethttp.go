@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"polyglot-codebase/go-service/middleware"
+)
+
+// NetHTTP returns a standard net/http middleware built on Core. It behaves
+// like middleware.CorrelationIDMiddleware, plus optional body capture.
+func (c *Core) NetHTTP() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reqBody []byte
+			if c.CaptureBody {
+				reqBody, r.Body = readAndRestore(r.Body)
+			}
+
+			span := c.Start(RequestInfo{
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Header: r.Header.Get,
+				Body:   reqBody,
+			})
+
+			ctx := context.WithValue(r.Context(), middleware.CorrelationIDKey, span.CorrelationID())
+			ctx = context.WithValue(ctx, middleware.TraceContextKey, span.TraceContext())
+			r = r.WithContext(ctx)
+
+			w.Header().Set(middleware.CorrelationIDHeader, span.CorrelationID())
+			w.Header().Set(middleware.TraceParentHeader, span.TraceParent())
+			if ts := span.TraceState(); ts != "" {
+				w.Header().Set(middleware.TraceStateHeader, ts)
+			}
+
+			wrapped := &statusCapturingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			if c.CaptureBody {
+				wrapped.tee = &bytes.Buffer{}
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			var respBody []byte
+			if wrapped.tee != nil {
+				respBody = wrapped.tee.Bytes()
+			}
+			span.Finish(wrapped.statusCode, respBody)
+		})
+	}
+}
+
+// statusCapturingWriter is the same status-capturing shim
+// middleware.CorrelationIDMiddleware uses, plus an optional tee buffer for
+// CaptureBody and Flush passthrough for streaming handlers.
+type statusCapturingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	wrote      bool
+	tee        *bytes.Buffer
+}
+
+func (w *statusCapturingWriter) WriteHeader(code int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusCapturingWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.tee != nil {
+		w.tee.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusCapturingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// readAndRestore reads body fully and returns a fresh ReadCloser that
+// replays the same bytes, so capturing a request body doesn't consume it
+// for the real handler.
+func readAndRestore(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	if body == nil {
+		return nil, http.NoBody
+	}
+	b, _ := io.ReadAll(body)
+	return b, io.NopCloser(bytes.NewReader(b))
+}
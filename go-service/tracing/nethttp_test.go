@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"polyglot-codebase/go-service/middleware"
+)
+
+func TestCore_NetHTTP_PropagatesCorrelationIDAndRecordsSpan(t *testing.T) {
+	core := NewCore()
+
+	var sawCorrelationID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sawCorrelationID, _ = r.Context().Value(middleware.CorrelationIDKey).(string)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	core.NetHTTP()(next).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotEmpty(t, sawCorrelationID)
+	assert.Equal(t, sawCorrelationID, rr.Header().Get(middleware.CorrelationIDHeader))
+	assert.NotEmpty(t, rr.Header().Get(middleware.TraceParentHeader))
+
+	traces := middleware.GetTraces(sawCorrelationID)
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, http.StatusCreated, traces[0].Status)
+	}
+}
+
+// TestCore_NetHTTP_BodyCapture shows request/response body capture toggled
+// via WithBodyCapture: off by default, attached to the recorded span's
+// Attributes when enabled.
+func TestCore_NetHTTP_BodyCapture(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "ping", string(body))
+		_, _ = w.Write([]byte("pong"))
+	})
+
+	var corrID string
+	capture := func(r *http.Request, core *Core) {
+		rr := httptest.NewRecorder()
+		core.NetHTTP()(next).ServeHTTP(rr, r)
+		corrID = rr.Header().Get(middleware.CorrelationIDHeader)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		core := NewCore()
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		capture(req, core)
+		traces := middleware.GetTraces(corrID)
+		if assert.Len(t, traces, 1) {
+			assert.Empty(t, traces[0].Attributes["request.body"])
+			assert.Empty(t, traces[0].Attributes["response.body"])
+		}
+	})
+
+	t.Run("enabled via WithBodyCapture", func(t *testing.T) {
+		core := NewCore(WithBodyCapture(0))
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+		capture(req, core)
+		traces := middleware.GetTraces(corrID)
+		if assert.Len(t, traces, 1) {
+			assert.Equal(t, "ping", traces[0].Attributes["request.body"])
+			assert.Equal(t, "pong", traces[0].Attributes["response.body"])
+		}
+	})
+}